@@ -8,6 +8,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 var _ = (*executionPayloadEnvelopeMarshaling)(nil)
@@ -15,26 +16,29 @@ var _ = (*executionPayloadEnvelopeMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (e ExecutionPayloadEnvelope) MarshalJSON() ([]byte, error) {
 	type ExecutionPayloadEnvelope struct {
-		ExecutionPayload *ExecutableData `json:"executionPayload"  gencodec:"required"`
-		BlockValue       *hexutil.Big    `json:"blockValue"  gencodec:"required"`
-		BlobsBundle      *BlobsBundleV1  `json:"blobsBundle"`
-		Override         bool            `json:"shouldOverrideBuilder"`
+		ExecutionPayload *ExecutableData    `json:"executionPayload"  gencodec:"required"`
+		BlockValue       *hexutil.Big       `json:"blockValue"  gencodec:"required"`
+		BlobsBundle      *BlobsBundleV1     `json:"blobsBundle"`
+		Override         bool               `json:"shouldOverrideBuilder"`
+		Profit           *types.BlockProfit `json:"profit,omitempty"`
 	}
 	var enc ExecutionPayloadEnvelope
 	enc.ExecutionPayload = e.ExecutionPayload
 	enc.BlockValue = (*hexutil.Big)(e.BlockValue)
 	enc.BlobsBundle = e.BlobsBundle
 	enc.Override = e.Override
+	enc.Profit = e.Profit
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (e *ExecutionPayloadEnvelope) UnmarshalJSON(input []byte) error {
 	type ExecutionPayloadEnvelope struct {
-		ExecutionPayload *ExecutableData `json:"executionPayload"  gencodec:"required"`
-		BlockValue       *hexutil.Big    `json:"blockValue"  gencodec:"required"`
-		BlobsBundle      *BlobsBundleV1  `json:"blobsBundle"`
-		Override         *bool           `json:"shouldOverrideBuilder"`
+		ExecutionPayload *ExecutableData    `json:"executionPayload"  gencodec:"required"`
+		BlockValue       *hexutil.Big       `json:"blockValue"  gencodec:"required"`
+		BlobsBundle      *BlobsBundleV1     `json:"blobsBundle"`
+		Override         *bool              `json:"shouldOverrideBuilder"`
+		Profit           *types.BlockProfit `json:"profit,omitempty"`
 	}
 	var dec ExecutionPayloadEnvelope
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -54,5 +58,8 @@ func (e *ExecutionPayloadEnvelope) UnmarshalJSON(input []byte) error {
 	if dec.Override != nil {
 		e.Override = *dec.Override
 	}
+	if dec.Profit != nil {
+		e.Profit = dec.Profit
+	}
 	return nil
 }
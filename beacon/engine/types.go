@@ -97,6 +97,9 @@ type ExecutionPayloadEnvelope struct {
 	BlockValue       *big.Int        `json:"blockValue"  gencodec:"required"`
 	BlobsBundle      *BlobsBundleV1  `json:"blobsBundle"`
 	Override         bool            `json:"shouldOverrideBuilder"`
+
+	// --- SUAVE SPECIFIC ---
+	Profit *types.BlockProfit `json:"profit,omitempty"` // breakdown of the block value, for blocks built by SUAVE
 }
 
 type BlobsBundleV1 struct {
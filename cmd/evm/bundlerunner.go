@@ -0,0 +1,182 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/params"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var InputBundlesFlag = &cli.StringFlag{
+	Name:  "input.bundles",
+	Usage: "`stdin` or file name of where to find the bundles to apply.",
+	Value: "bundles.json",
+}
+
+var bundleCommand = &cli.Command{
+	Action: bundleCmd,
+	Name:   "bundle",
+	Usage:  "Applies bundles to a prestate with the semantics of the block builder",
+	Flags: []cli.Flag{
+		t8ntool.InputAllocFlag,
+		t8ntool.InputEnvFlag,
+		InputBundlesFlag,
+		t8ntool.ForknameFlag,
+		t8ntool.ChainIDFlag,
+	},
+}
+
+// bundleEnv holds the fields of the header of the block the bundles are
+// applied to.
+type bundleEnv struct {
+	Coinbase      common.Address        `json:"currentCoinbase"`
+	GasLimit      math.HexOrDecimal64   `json:"currentGasLimit"`
+	Number        math.HexOrDecimal64   `json:"currentNumber"`
+	Timestamp     math.HexOrDecimal64   `json:"currentTimestamp"`
+	Difficulty    *math.HexOrDecimal256 `json:"currentDifficulty,omitempty"`
+	Random        *math.HexOrDecimal256 `json:"currentRandom,omitempty"`
+	BaseFee       *math.HexOrDecimal256 `json:"currentBaseFee,omitempty"`
+	ExcessBlobGas *math.HexOrDecimal64  `json:"currentExcessBlobGas,omitempty"`
+	ParentHash    common.Hash           `json:"parentHash"`
+}
+
+// bundleResult is the output of the bundle command.
+type bundleResult struct {
+	Results   []*suavextypes.SimulateBundleResult `json:"results"`
+	StateRoot common.Hash                         `json:"stateRoot"`
+	Alloc     t8ntool.Alloc                       `json:"alloc"`
+}
+
+func bundleCmd(ctx *cli.Context) error {
+	var (
+		alloc   types.GenesisAlloc
+		env     bundleEnv
+		bundles []*suavextypes.Bundle
+	)
+	if err := readJSONInput(ctx.String(t8ntool.InputAllocFlag.Name), &alloc); err != nil {
+		return fmt.Errorf("failed reading alloc: %v", err)
+	}
+	if err := readJSONInput(ctx.String(t8ntool.InputEnvFlag.Name), &env); err != nil {
+		return fmt.Errorf("failed reading env: %v", err)
+	}
+	if err := readJSONInput(ctx.String(InputBundlesFlag.Name), &bundles); err != nil {
+		return fmt.Errorf("failed reading bundles: %v", err)
+	}
+
+	chainConfig, _, err := tests.GetChainConfig(ctx.String(t8ntool.ForknameFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed constructing chain configuration: %v", err)
+	}
+	chainConfig.ChainID = big.NewInt(ctx.Int64(t8ntool.ChainIDFlag.Name))
+
+	header, err := env.header(chainConfig)
+	if err != nil {
+		return err
+	}
+
+	// The bundles are applied as a whole like in a builder session, if any of
+	// them fails the post-state is the prestate.
+	statedb := t8ntool.MakePreState(rawdb.NewMemoryDatabase(), alloc)
+	builder := miner.NewBuilderFromState(chainConfig, header, statedb)
+	results, err := builder.AddBundles(context.Background(), bundles)
+	if err != nil {
+		return err
+	}
+
+	statedb = builder.State()
+	root, err := statedb.Commit(header.Number.Uint64(), chainConfig.IsEIP158(header.Number))
+	if err != nil {
+		return fmt.Errorf("failed committing state: %v", err)
+	}
+	statedb, err = state.New(root, statedb.Database(), nil)
+	if err != nil {
+		return err
+	}
+	collector := make(t8ntool.Alloc)
+	statedb.DumpToCollector(collector, nil)
+
+	out, err := json.MarshalIndent(&bundleResult{
+		Results:   results,
+		StateRoot: root,
+		Alloc:     collector,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// header creates the header of the block described by the env.
+func (env *bundleEnv) header(chainConfig *params.ChainConfig) (*types.Header, error) {
+	header := &types.Header{
+		ParentHash: env.ParentHash,
+		Coinbase:   env.Coinbase,
+		Difficulty: new(big.Int),
+		Number:     new(big.Int).SetUint64(uint64(env.Number)),
+		GasLimit:   uint64(env.GasLimit),
+		Time:       uint64(env.Timestamp),
+	}
+	if env.Difficulty != nil {
+		header.Difficulty = (*big.Int)(env.Difficulty)
+	}
+	if env.Random != nil {
+		header.MixDigest = common.BigToHash((*big.Int)(env.Random))
+	}
+	if chainConfig.IsLondon(header.Number) {
+		if env.BaseFee == nil {
+			return nil, errors.New("EIP-1559 config but missing 'currentBaseFee' in env section")
+		}
+		header.BaseFee = (*big.Int)(env.BaseFee)
+	}
+	if chainConfig.IsCancun(header.Number, header.Time) {
+		header.ExcessBlobGas = new(uint64)
+		if env.ExcessBlobGas != nil {
+			*header.ExcessBlobGas = uint64(*env.ExcessBlobGas)
+		}
+	}
+	return header, nil
+}
+
+// readJSONInput decodes the JSON content of the given file, or of the standard
+// input if the name is "stdin".
+func readJSONInput(name string, v interface{}) error {
+	if name == "stdin" {
+		return json.NewDecoder(os.Stdin).Decode(v)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
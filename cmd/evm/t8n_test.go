@@ -672,6 +672,55 @@ func TestB11r(t *testing.T) {
 	}
 }
 
+func TestBundle(t *testing.T) {
+	t.Parallel()
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+	for i, tc := range []struct {
+		base    string
+		bundles string
+		expOut  string
+	}{
+		{ // reverting hashes and inclusion range
+			base:    "./testdata/33",
+			bundles: "bundles.json",
+			expOut:  "exp.json",
+		},
+		{ // invalid inclusion range
+			base:    "./testdata/33",
+			bundles: "bundles-range.json",
+			expOut:  "exp-range.json",
+		},
+	} {
+		args := []string{"bundle",
+			"--input.alloc", fmt.Sprintf("%v/alloc.json", tc.base),
+			"--input.env", fmt.Sprintf("%v/env.json", tc.base),
+			"--input.bundles", fmt.Sprintf("%v/%v", tc.base, tc.bundles),
+			"--state.fork", "Cancun",
+		}
+		tt.Run("evm-test", args...)
+		tt.Logf("args:\n go run . %v\n", strings.Join(args, " "))
+
+		want, err := os.ReadFile(fmt.Sprintf("%v/%v", tc.base, tc.expOut))
+		if err != nil {
+			t.Fatalf("test %d: could not read expected output: %v", i, err)
+		}
+		have := tt.Output()
+		ok, err := cmpJson(have, want)
+		switch {
+		case err != nil:
+			t.Logf(string(have))
+			t.Fatalf("test %d, json parsing failed: %v", i, err)
+		case !ok:
+			t.Fatalf("test %d: output wrong, have \n%v\nwant\n%v\n", i, string(have), string(want))
+		}
+		tt.WaitExit()
+		if have := tt.ExitStatus(); have != 0 {
+			t.Fatalf("test %d: wrong exit code, have %d, want 0", i, have)
+		}
+	}
+}
+
 // cmpJson compares the JSON in two byte slices.
 func cmpJson(a, b []byte) (bool, error) {
 	var j, j2 interface{}
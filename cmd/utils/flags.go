@@ -59,6 +59,7 @@ import (
 	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
@@ -507,6 +508,11 @@ var (
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
 		Category: flags.MinerCategory,
 	}
+	MinerDenyListFlag = &cli.StringFlag{
+		Name:     "miner.denylist",
+		Usage:    "JSON file with the addresses that built blocks must not touch (reloadable with admin_reloadDenyList)",
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -954,6 +960,23 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    metrics.DefaultConfig.InfluxDBOrganization,
 		Category: flags.MetricsCategory,
 	}
+
+	TracingEndpointFlag = &cli.StringFlag{
+		Name:     "tracing.endpoint",
+		Usage:    "OTLP/HTTP traces endpoint the spans of the RPC requests are exported to (e.g. http://localhost:4318/v1/traces)",
+		Category: flags.MetricsCategory,
+	}
+	TracingFileFlag = &cli.StringFlag{
+		Name:     "tracing.file",
+		Usage:    "File the spans of the RPC requests are appended to, one JSON span per line",
+		Category: flags.MetricsCategory,
+	}
+	TracingNamespacesFlag = &cli.StringFlag{
+		Name:     "tracing.namespaces",
+		Usage:    "Comma separated list of the RPC namespaces whose calls are traced",
+		Value:    strings.Join(telemetry.DefaultNamespaces, ","),
+		Category: flags.MetricsCategory,
+	}
 )
 
 var (
@@ -1408,6 +1431,15 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(JWTSecretFlag.Name) {
 		cfg.JWTSecret = ctx.String(JWTSecretFlag.Name)
 	}
+	if ctx.IsSet(TracingEndpointFlag.Name) {
+		cfg.Tracing.Endpoint = ctx.String(TracingEndpointFlag.Name)
+	}
+	if ctx.IsSet(TracingFileFlag.Name) {
+		cfg.Tracing.File = ctx.String(TracingFileFlag.Name)
+	}
+	if ctx.IsSet(TracingNamespacesFlag.Name) {
+		cfg.Tracing.Namespaces = SplitAndTrim(ctx.String(TracingNamespacesFlag.Name))
+	}
 
 	if ctx.IsSet(EnablePersonal.Name) {
 		cfg.EnablePersonal = true
@@ -1565,6 +1597,9 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		log.Warn("The flag --miner.newpayload-timeout is deprecated and will be removed, please use --miner.recommit")
 		cfg.Recommit = ctx.Duration(MinerNewPayloadTimeoutFlag.Name)
 	}
+	if ctx.IsSet(MinerDenyListFlag.Name) {
+		cfg.DenyList = ctx.String(MinerDenyListFlag.Name)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -114,6 +114,11 @@ type StateDB struct {
 	validRevisions []revision
 	nextRevisionId int
 
+	// --- SUAVE SPECIFIC ---
+	// Journal of the finalised transactions, kept while a checkpoint is open.
+	checkpoints       []int // lengths of the checkpoint journal at the open checkpoints
+	checkpointJournal []journalEntry
+
 	// Measurements gathered during execution for debugging purposes
 	AccountReads         time.Duration
 	AccountHashes        time.Duration
@@ -824,6 +829,9 @@ func (s *StateDB) GetRefund() uint64 {
 // the journal as well as the refunds. Finalise, however, will not push any updates
 // into the tries just yet. Only IntermediateRoot or Commit will do that.
 func (s *StateDB) Finalise(deleteEmptyObjects bool) {
+	// --- SUAVE SPECIFIC ---
+	s.keepJournal()
+
 	addressesToPrefetch := make([][]byte, 0, len(s.journal.dirties))
 	for addr := range s.journal.dirties {
 		obj, exist := s.stateObjects[addr]
@@ -836,6 +844,10 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 			// Thus, we can safely ignore it here
 			continue
 		}
+		// --- SUAVE SPECIFIC ---
+		if len(s.checkpoints) > 0 {
+			s.checkpointJournal = append(s.checkpointJournal, newFinaliseChange(s, obj))
+		}
 		if obj.selfDestructed || (deleteEmptyObjects && obj.empty()) {
 			obj.deleted = true
 
@@ -882,6 +894,10 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// Finalise all the dirty storage states and write them into the tries
 	s.Finalise(deleteEmptyObjects)
 
+	// --- SUAVE SPECIFIC ---
+	// The changes written into the tries can't be reverted.
+	s.dropCheckpoints()
+
 	// If there was a trie prefetcher operating, it gets aborted and irrevocably
 	// modified after we start retrieving tries. Remove it from the statedb after
 	// this round of use.
@@ -0,0 +1,160 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Checkpoint returns the id of a revision of the state that, unlike the ones
+// of Snapshot, can be reverted to after the end of the transactions applied
+// since. It allows to roll back a batch of transactions at a cost proportional
+// to the changes they made, instead of copying the state beforehand.
+//
+// A checkpoint must be taken between transactions. The journal of the
+// finalised transactions is kept until the checkpoint is reverted or
+// discarded, and IntermediateRoot discards every checkpoint as the changes it
+// writes into the tries can't be reverted. The copies of the state don't
+// inherit the checkpoints.
+func (s *StateDB) Checkpoint() int {
+	s.checkpoints = append(s.checkpoints, len(s.checkpointJournal))
+	return len(s.checkpoints) - 1
+}
+
+// RevertToCheckpoint reverts the changes made since the checkpoint, including
+// the ones of the transaction in progress. The checkpoint and the later ones
+// are discarded.
+func (s *StateDB) RevertToCheckpoint(id int) {
+	if id < 0 || id >= len(s.checkpoints) {
+		panic(fmt.Errorf("checkpoint %d cannot be reverted", id))
+	}
+	s.journal.revert(s, 0)
+	s.validRevisions = s.validRevisions[:0]
+
+	length := s.checkpoints[id]
+	for i := len(s.checkpointJournal) - 1; i >= length; i-- {
+		s.checkpointJournal[i].revert(s)
+	}
+	s.checkpointJournal = s.checkpointJournal[:length]
+	s.DiscardCheckpoint(id)
+}
+
+// DiscardCheckpoint keeps the changes made since the checkpoint, which can
+// still be reverted with the earlier checkpoints. The checkpoint and the later
+// ones are discarded.
+func (s *StateDB) DiscardCheckpoint(id int) {
+	if id < 0 || id >= len(s.checkpoints) {
+		return
+	}
+	s.checkpoints = s.checkpoints[:id]
+	if len(s.checkpoints) == 0 {
+		s.checkpointJournal = nil
+	}
+}
+
+// dropCheckpoints discards every checkpoint.
+func (s *StateDB) dropCheckpoints() {
+	s.checkpoints = nil
+	s.checkpointJournal = nil
+}
+
+// keepJournal moves the journal of the finalised transaction to the one of
+// the checkpoints, if there is one open. The changes to the access list and
+// the transient storage are left out, they don't outlive the transaction.
+func (s *StateDB) keepJournal() {
+	if len(s.checkpoints) == 0 || len(s.journal.entries) == 0 {
+		return
+	}
+	for _, entry := range s.journal.entries {
+		switch entry.(type) {
+		case accessListAddAccountChange, accessListAddSlotChange, transientStorageChange, touchChange:
+			continue
+		}
+		s.checkpointJournal = append(s.checkpointJournal, entry)
+	}
+	// the refund is cleared along with the journal
+	if s.refund != 0 {
+		s.checkpointJournal = append(s.checkpointJournal, refundChange{prev: s.refund})
+	}
+}
+
+// finaliseChange reverts the finalisation of a state object at the end of a
+// transaction.
+type finaliseChange struct {
+	obj                            *stateObject
+	deleted, created               bool // flags of the object
+	pending, dirty, destructed     bool // whether the object was already tracked as such
+	prevAccount, prevAccountOrigin []byte
+	prevAccountOriginExist         bool
+	prevStorage, prevStorageOrigin map[common.Hash][]byte
+	dirtyStorage                   Storage // dirty slots moved to the pending area
+	pendingStorage                 Storage // pending values overwritten by the dirty slots
+}
+
+// newFinaliseChange records the state of the object before it is finalised.
+func newFinaliseChange(s *StateDB, obj *stateObject) finaliseChange {
+	ch := finaliseChange{
+		obj:               obj,
+		deleted:           obj.deleted,
+		created:           obj.created,
+		prevAccount:       s.accounts[obj.addrHash],
+		prevStorage:       s.storages[obj.addrHash],
+		prevStorageOrigin: s.storagesOrigin[obj.address],
+	}
+	_, ch.pending = s.stateObjectsPending[obj.address]
+	_, ch.dirty = s.stateObjectsDirty[obj.address]
+	_, ch.destructed = s.stateObjectsDestruct[obj.address]
+	ch.prevAccountOrigin, ch.prevAccountOriginExist = s.accountsOrigin[obj.address]
+
+	// the dirty storage is replaced, not cleared, by the finalisation
+	if len(obj.dirtyStorage) > 0 {
+		ch.dirtyStorage = obj.dirtyStorage
+		ch.pendingStorage = make(Storage)
+		for key := range obj.dirtyStorage {
+			if value, ok := obj.pendingStorage[key]; ok {
+				ch.pendingStorage[key] = value
+			}
+		}
+	}
+	return ch
+}
+
+func (ch finaliseChange) revert(s *StateDB) {
+	obj := ch.obj
+	obj.deleted, obj.created = ch.deleted, ch.created
+	if !ch.pending {
+		delete(s.stateObjectsPending, obj.address)
+	}
+	if !ch.dirty {
+		delete(s.stateObjectsDirty, obj.address)
+	}
+	if !ch.destructed {
+		delete(s.stateObjectsDestruct, obj.address)
+	}
+	if ch.prevAccount != nil {
+		s.accounts[obj.addrHash] = ch.prevAccount
+	}
+	if ch.prevStorage != nil {
+		s.storages[obj.addrHash] = ch.prevStorage
+	}
+	if ch.prevAccountOriginExist {
+		s.accountsOrigin[obj.address] = ch.prevAccountOrigin
+	}
+	if ch.prevStorageOrigin != nil {
+		s.storagesOrigin[obj.address] = ch.prevStorageOrigin
+	}
+	if ch.dirtyStorage != nil {
+		for key := range ch.dirtyStorage {
+			if value, ok := ch.pendingStorage[key]; ok {
+				obj.pendingStorage[key] = value
+			} else {
+				delete(obj.pendingStorage, key)
+			}
+		}
+		obj.dirtyStorage = ch.dirtyStorage
+	}
+}
+
+func (ch finaliseChange) dirtied() *common.Address {
+	return nil
+}
@@ -0,0 +1,220 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// newCheckpointTestState returns a state with committed accounts, one of them
+// holding storage and code.
+func newCheckpointTestState(t testing.TB) *StateDB {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(types.EmptyRootHash, db, nil)
+	for i := byte(1); i <= 4; i++ {
+		addr := common.Address{i}
+		state.AddBalance(addr, uint256.NewInt(uint64(i)*100), tracing.BalanceChangeUnspecified)
+		state.SetNonce(addr, uint64(i))
+	}
+	state.SetCode(common.Address{1}, []byte{0x60, 0x00})
+	state.SetState(common.Address{1}, common.Hash{1}, common.Hash{1})
+	state.SetState(common.Address{1}, common.Hash{2}, common.Hash{2})
+
+	root, err := state.Commit(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err = New(root, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+// applyCheckpointTestTxs makes changes of every kind over several finalised
+// transactions.
+func applyCheckpointTestTxs(state *StateDB) {
+	var (
+		a1, a2, a3 = common.Address{1}, common.Address{2}, common.Address{3}
+		fresh      = common.Address{0xf0}
+		empty      = common.Address{0xf1}
+	)
+	// storage and balances, finalised twice
+	state.SetState(a1, common.Hash{1}, common.Hash{0x11})
+	state.SetState(a1, common.Hash{3}, common.Hash{3})
+	state.AddBalance(a2, uint256.NewInt(5), tracing.BalanceChangeUnspecified)
+	state.AddRefund(100)
+	state.AddLog(&types.Log{Address: a1})
+	state.Finalise(true)
+
+	state.SetState(a1, common.Hash{1}, common.Hash{0x12})
+	state.SetState(a1, common.Hash{2}, common.Hash{})
+	state.SubBalance(a2, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	state.AddSlotToAccessList(a1, common.Hash{9})
+	state.SetTransientState(a1, common.Hash{9}, common.Hash{9})
+	state.Finalise(true)
+
+	// new accounts, a self-destruct and a touched empty account
+	state.CreateAccount(fresh)
+	state.SetNonce(fresh, 1)
+	state.SetCode(fresh, []byte{0x01})
+	state.SetState(fresh, common.Hash{1}, common.Hash{1})
+	state.SelfDestruct(a3)
+	state.AddBalance(empty, new(uint256.Int), tracing.BalanceChangeUnspecified)
+	state.Finalise(true)
+
+	// resurrection of the destructed account
+	state.CreateAccount(a3)
+	state.SetState(a3, common.Hash{5}, common.Hash{5})
+	state.SetNonce(a1, 10)
+}
+
+func TestCheckpointRevert(t *testing.T) {
+	state := newCheckpointTestState(t)
+	state.SetState(common.Address{4}, common.Hash{4}, common.Hash{4})
+	state.Finalise(true)
+
+	want := state.Copy()
+	wantLogs := len(state.Logs())
+
+	id := state.Checkpoint()
+	applyCheckpointTestTxs(state)
+	if state.GetState(common.Address{1}, common.Hash{1}) == want.GetState(common.Address{1}, common.Hash{1}) {
+		t.Fatal("changes not applied")
+	}
+	state.RevertToCheckpoint(id)
+
+	for i := byte(1); i <= 4; i++ {
+		addr := common.Address{i}
+		if have, want := state.GetBalance(addr), want.GetBalance(addr); !have.Eq(want) {
+			t.Errorf("account %x: balance %v, want %v", addr, have, want)
+		}
+		if have, want := state.GetNonce(addr), want.GetNonce(addr); have != want {
+			t.Errorf("account %x: nonce %d, want %d", addr, have, want)
+		}
+	}
+	for _, key := range []common.Hash{{1}, {2}, {3}} {
+		if have, want := state.GetState(common.Address{1}, key), want.GetState(common.Address{1}, key); have != want {
+			t.Errorf("slot %x: have %x, want %x", key, have, want)
+		}
+	}
+	if !state.Exist(common.Address{3}) || state.Exist(common.Address{0xf0}) || state.Exist(common.Address{0xf1}) {
+		t.Error("account existence not reverted")
+	}
+	if state.GetRefund() != 0 {
+		t.Errorf("refund %d, want 0", state.GetRefund())
+	}
+	if have := len(state.Logs()); have != wantLogs {
+		t.Errorf("%d logs, want %d", have, wantLogs)
+	}
+	if have, want := state.Copy().IntermediateRoot(true), want.Copy().IntermediateRoot(true); have != want {
+		t.Errorf("root %x after revert, want %x", have, want)
+	}
+
+	// the reverted state can be built upon like the copy
+	applyCheckpointTestTxs(state)
+	applyCheckpointTestTxs(want)
+	if have, want := state.IntermediateRoot(true), want.IntermediateRoot(true); have != want {
+		t.Errorf("root %x, want %x", have, want)
+	}
+}
+
+func TestCheckpointNested(t *testing.T) {
+	var (
+		addr = common.Address{1}
+		key  = common.Hash{1}
+	)
+	state := newCheckpointTestState(t)
+
+	outer := state.Checkpoint()
+	state.SetState(addr, key, common.Hash{0xa})
+	state.Finalise(true)
+
+	// the inner changes are kept once the inner checkpoint is discarded
+	inner := state.Checkpoint()
+	state.SetState(addr, key, common.Hash{0xb})
+	state.Finalise(true)
+	state.DiscardCheckpoint(inner)
+	if have := state.GetState(addr, key); have != (common.Hash{0xb}) {
+		t.Fatalf("have %x after discard", have)
+	}
+
+	// and reverted along with the outer ones
+	inner = state.Checkpoint()
+	state.SetState(addr, key, common.Hash{0xc})
+	state.Finalise(true)
+	state.RevertToCheckpoint(inner)
+	if have := state.GetState(addr, key); have != (common.Hash{0xb}) {
+		t.Fatalf("have %x after inner revert", have)
+	}
+	state.RevertToCheckpoint(outer)
+	if have := state.GetState(addr, key); have != (common.Hash{1}) {
+		t.Fatalf("have %x after outer revert", have)
+	}
+	if len(state.checkpoints) != 0 || state.checkpointJournal != nil {
+		t.Fatal("checkpoint journal left after the last revert")
+	}
+
+	// the journal is dropped once the last checkpoint is discarded
+	state.Checkpoint()
+	state.SetState(addr, key, common.Hash{0xd})
+	state.Finalise(true)
+	state.DiscardCheckpoint(0)
+	if state.checkpointJournal != nil {
+		t.Fatal("checkpoint journal left after the last discard")
+	}
+}
+
+func TestCheckpointIntermediateRoot(t *testing.T) {
+	state := newCheckpointTestState(t)
+	id := state.Checkpoint()
+	state.SetState(common.Address{1}, common.Hash{1}, common.Hash{0xa})
+	state.IntermediateRoot(true)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic reverting a checkpoint dropped by IntermediateRoot")
+		}
+	}()
+	state.RevertToCheckpoint(id)
+}
+
+// BenchmarkCheckpointRevert rolls back a batch of transactions on top of a state
+// with many changed accounts, with a checkpoint or by dropping a copy.
+func BenchmarkCheckpointRevert(b *testing.B) {
+	newState := func(b *testing.B) *StateDB {
+		state := newCheckpointTestState(b)
+		for i := 0; i < 200; i++ {
+			addr := common.BytesToAddress([]byte{0xa0, byte(i)})
+			state.AddBalance(addr, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+			state.SetState(addr, common.Hash{1}, common.Hash{1})
+		}
+		state.Finalise(true)
+		return state
+	}
+	b.Run("checkpoint", func(b *testing.B) {
+		state := newState(b)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			id := state.Checkpoint()
+			applyCheckpointTestTxs(state)
+			state.Finalise(true)
+			state.RevertToCheckpoint(id)
+		}
+	})
+	b.Run("copy", func(b *testing.B) {
+		state := newState(b)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			cpy := state.Copy()
+			applyCheckpointTestTxs(cpy)
+			cpy.Finalise(true)
+		}
+	})
+}
@@ -0,0 +1,472 @@
+// Package privatepool implements a transaction subpool for the transactions
+// sent privately to the builder. The transactions are only made available
+// to the block building paths that explicitly request them and are never
+// announced, propagated or served to peers.
+//
+// Like every subpool, the private pool reserves the senders of its transactions
+// in the main transaction pool. While an account has private transactions, its
+// public transactions are rejected with txpool.ErrAlreadyReserved, and an
+// account with public transactions cannot send private ones until they are
+// included or dropped. The nonces of a sender are thus never split between the
+// public and the private transactions.
+package privatepool
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// txMaxSize is the maximum size a single private transaction can have.
+const txMaxSize = 128 * 1024
+
+var (
+	ErrPoolFull            = errors.New("private pool is full")
+	ErrTxExpired           = errors.New("private transaction max block is over")
+	ErrTxNotFound          = errors.New("private transaction not found")
+	ErrPublicSubmission    = errors.New("private pool does not accept public transactions")
+	ErrReplaceUnderpriced  = errors.New("replacement private transaction underpriced")
+	ErrAlreadyReservedAddr = errors.New("sender has pending public transactions")
+)
+
+// Config are the configuration parameters of the private transaction pool.
+type Config struct {
+	MaxTxs          int    // Maximum number of private transactions kept by the pool
+	AccountSlots    int    // Maximum number of private transactions per account
+	DefaultLifetime uint64 // Number of blocks a transaction without max block is kept
+}
+
+// DefaultConfig contains the default configurations for the private pool.
+var DefaultConfig = Config{
+	MaxTxs:          4096,
+	AccountSlots:    16,
+	DefaultLifetime: 25,
+}
+
+// BlockChain defines the minimal set of methods needed to back a private pool
+// with a chain. Exists to allow mocking the live chain out of tests.
+type BlockChain interface {
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// StateAt returns a state database for a given root hash (generally the head).
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// privateTx is a pooled transaction together with its inclusion limit.
+type privateTx struct {
+	tx       *types.Transaction
+	from     common.Address
+	client   string // client that sent the transaction
+	maxBlock uint64
+	time     time.Time
+}
+
+// PrivatePool keeps the private transactions until they are included on-chain,
+// their max block is over or they are cancelled.
+type PrivatePool struct {
+	config Config
+	chain  BlockChain
+	signer types.Signer
+
+	reserve txpool.AddressReserver
+
+	mu       sync.RWMutex
+	head     *types.Header
+	state    *state.StateDB
+	gasTip   *uint256.Int
+	all      map[common.Hash]*privateTx
+	accounts map[common.Address][]*privateTx // sorted by nonce
+}
+
+// New creates a new private transaction pool. The pool has to be registered
+// as a subpool of the main transaction pool to be kept in sync with the chain.
+func New(config Config, chain BlockChain) *PrivatePool {
+	if config.MaxTxs <= 0 {
+		config.MaxTxs = DefaultConfig.MaxTxs
+	}
+	if config.AccountSlots <= 0 {
+		config.AccountSlots = DefaultConfig.AccountSlots
+	}
+	if config.DefaultLifetime == 0 {
+		config.DefaultLifetime = DefaultConfig.DefaultLifetime
+	}
+	return &PrivatePool{
+		config:   config,
+		chain:    chain,
+		signer:   types.LatestSigner(chain.Config()),
+		gasTip:   new(uint256.Int),
+		all:      make(map[common.Hash]*privateTx),
+		accounts: make(map[common.Address][]*privateTx),
+	}
+}
+
+// Filter returns false for every transaction, private transactions can only
+// enter the pool through AddPrivate.
+func (p *PrivatePool) Filter(tx *types.Transaction) bool {
+	return false
+}
+
+// Init sets the gas price needed to keep a transaction in the pool and the chain
+// head to run the state validations against.
+func (p *PrivatePool) Init(gasTip uint64, head *types.Header, reserve txpool.AddressReserver) error {
+	statedb, err := p.chain.StateAt(head.Root)
+	if err != nil {
+		statedb, err = p.chain.StateAt(types.EmptyRootHash)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reserve = reserve
+	p.gasTip = uint256.NewInt(gasTip)
+	p.head = head
+	p.state = statedb
+	return nil
+}
+
+// Close terminates the pool, the private transactions are not persisted.
+func (p *PrivatePool) Close() error {
+	return nil
+}
+
+// Reset drops the transactions that were included in the new head and the
+// ones whose max block is over.
+func (p *PrivatePool) Reset(oldHead, newHead *types.Header) {
+	statedb, err := p.chain.StateAt(newHead.Root)
+	if err != nil {
+		log.Error("Failed to reset private pool state", "err", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.head = newHead
+	p.state = statedb
+
+	number := newHead.Number.Uint64()
+	for addr, txs := range p.accounts {
+		nonce := statedb.GetNonce(addr)
+
+		kept := txs[:0]
+		for _, ptx := range txs {
+			if ptx.tx.Nonce() < nonce || ptx.maxBlock <= number {
+				delete(p.all, ptx.tx.Hash())
+				continue
+			}
+			kept = append(kept, ptx)
+		}
+		p.setAccount(addr, kept)
+	}
+}
+
+// setAccount updates the transactions of an account and releases the address
+// reservation once the account has none left.
+func (p *PrivatePool) setAccount(addr common.Address, txs []*privateTx) {
+	if len(txs) > 0 {
+		p.accounts[addr] = txs
+		return
+	}
+	delete(p.accounts, addr)
+	if err := p.reserve(addr, false); err != nil {
+		log.Error("Failed to release private pool address", "addr", addr, "err", err)
+	}
+}
+
+// SetGasTip updates the minimum tip required for new private transactions.
+// Already pooled transactions are kept since they were sent to this builder.
+func (p *PrivatePool) SetGasTip(tip *big.Int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gasTip = uint256.MustFromBig(tip)
+}
+
+// Has returns false for every transaction, the private transactions are
+// never exposed through the lookups used to serve peers and RPC users.
+func (p *PrivatePool) Has(hash common.Hash) bool {
+	return false
+}
+
+// Get returns nil for every transaction, see Has.
+func (p *PrivatePool) Get(hash common.Hash) *types.Transaction {
+	return nil
+}
+
+// Add rejects every transaction, public submissions are never routed to the
+// private pool.
+func (p *PrivatePool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i := range txs {
+		errs[i] = ErrPublicSubmission
+	}
+	return errs
+}
+
+// AddPrivate validates the transaction sent by the given client and keeps it
+// until the given block number. A zero max block keeps the transaction for the
+// default lifetime. It fails with ErrAlreadyReservedAddr if the sender has
+// transactions in another subpool, see the package documentation.
+func (p *PrivatePool) AddPrivate(tx *types.Transaction, maxBlock uint64, client string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	opts := &txpool.ValidationOptions{
+		Config: p.chain.Config(),
+		Accept: 0 |
+			1<<types.LegacyTxType |
+			1<<types.AccessListTxType |
+			1<<types.DynamicFeeTxType,
+		MaxSize: txMaxSize,
+		MinTip:  p.gasTip.ToBig(),
+	}
+	if err := txpool.ValidateTransaction(tx, p.head, p.signer, opts); err != nil {
+		return err
+	}
+
+	number := p.head.Number.Uint64()
+	if maxBlock == 0 {
+		maxBlock = number + p.config.DefaultLifetime
+	}
+	if maxBlock <= number {
+		return fmt.Errorf("%w: max block %d, head %d", ErrTxExpired, maxBlock, number)
+	}
+
+	from, _ := types.Sender(p.signer, tx) // already validated above
+	txs := p.accounts[from]
+
+	stateOpts := &txpool.ValidationOptionsWithState{
+		State: p.state,
+		FirstNonceGap: func(addr common.Address) uint64 {
+			return p.nextNonce(addr)
+		},
+		UsedAndLeftSlots: func(addr common.Address) (int, int) {
+			return len(txs), p.config.AccountSlots - len(txs)
+		},
+		ExistingExpenditure: func(addr common.Address) *big.Int {
+			spent := new(big.Int)
+			for _, ptx := range txs {
+				spent.Add(spent, ptx.tx.Cost())
+			}
+			return spent
+		},
+		ExistingCost: func(addr common.Address, nonce uint64) *big.Int {
+			if ptx := findNonce(txs, nonce); ptx != nil {
+				return ptx.tx.Cost()
+			}
+			return nil
+		},
+	}
+	if err := txpool.ValidateTransactionWithState(tx, p.signer, stateOpts); err != nil {
+		return err
+	}
+
+	hash := tx.Hash()
+	if _, ok := p.all[hash]; ok {
+		return txpool.ErrAlreadyKnown
+	}
+
+	ptx := &privateTx{tx: tx, from: from, client: client, maxBlock: maxBlock, time: time.Now()}
+	if prev := findNonce(txs, tx.Nonce()); prev != nil {
+		if prev.tx.GasFeeCapIntCmp(tx.GasFeeCap()) >= 0 || prev.tx.GasTipCapIntCmp(tx.GasTipCap()) >= 0 {
+			return ErrReplaceUnderpriced
+		}
+		delete(p.all, prev.tx.Hash())
+		*prev = *ptx
+		p.all[hash] = prev
+		return nil
+	}
+
+	if len(p.all) >= p.config.MaxTxs {
+		return ErrPoolFull
+	}
+	if len(txs) == 0 {
+		if err := p.reserve(from, true); err != nil {
+			return fmt.Errorf("%w: %s (%v)", ErrAlreadyReservedAddr, from, err)
+		}
+	}
+	txs = append(txs, ptx)
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].tx.Nonce() < txs[j].tx.Nonce()
+	})
+	p.accounts[from] = txs
+	p.all[hash] = ptx
+
+	log.Debug("Private transaction added", "hash", hash, "from", from, "maxBlock", maxBlock)
+	return nil
+}
+
+// Cancel removes a private transaction from the pool. The later transactions
+// of the same sender are kept but will not be executable until the nonce gap
+// is filled again.
+func (p *PrivatePool) Cancel(hash common.Hash) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ptx, ok := p.all[hash]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTxNotFound, hash)
+	}
+	delete(p.all, hash)
+
+	txs := p.accounts[ptx.from]
+	kept := make([]*privateTx, 0, len(txs)-1)
+	for _, other := range txs {
+		if other != ptx {
+			kept = append(kept, other)
+		}
+	}
+	p.setAccount(ptx.from, kept)
+
+	log.Debug("Private transaction cancelled", "hash", hash)
+	return nil
+}
+
+// SentBy returns whether the transaction was sent to the pool by the given
+// client.
+func (p *PrivatePool) SentBy(hash common.Hash, client string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ptx, ok := p.all[hash]
+	return ok && ptx.client == client
+}
+
+// Pending retrieves the executable private transactions, grouped by sender
+// and sorted by nonce. Transactions are only returned if the filter requests
+// the private ones.
+func (p *PrivatePool) Pending(filter txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction {
+	if !filter.IncludePrivateTxs || filter.OnlyBlobTxs {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var (
+		minTipBig  *big.Int
+		baseFeeBig *big.Int
+	)
+	if filter.MinTip != nil {
+		minTipBig = filter.MinTip.ToBig()
+	}
+	if filter.BaseFee != nil {
+		baseFeeBig = filter.BaseFee.ToBig()
+	}
+
+	pending := make(map[common.Address][]*txpool.LazyTransaction, len(p.accounts))
+	for addr, txs := range p.accounts {
+		nonce := p.state.GetNonce(addr)
+
+		var lazies []*txpool.LazyTransaction
+		for _, ptx := range txs {
+			tx := ptx.tx
+			if tx.Nonce() != nonce {
+				break
+			}
+			if minTipBig != nil && tx.EffectiveGasTipIntCmp(minTipBig, baseFeeBig) < 0 {
+				break
+			}
+			lazies = append(lazies, &txpool.LazyTransaction{
+				Pool:      p,
+				Hash:      tx.Hash(),
+				Tx:        tx,
+				Time:      ptx.time,
+				GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+				GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+				Gas:       tx.Gas(),
+				BlobGas:   tx.BlobGas(),
+			})
+			nonce++
+		}
+		if len(lazies) > 0 {
+			pending[addr] = lazies
+		}
+	}
+	return pending
+}
+
+// SubscribeTransactions returns a subscription that never delivers events, so
+// that the private transactions are never announced to peers.
+func (p *PrivatePool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// Nonce returns the state nonce of an account, the pooled private transactions
+// are not applied on top so that they cannot be observed through the pending nonce.
+func (p *PrivatePool) Nonce(addr common.Address) uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.state.GetNonce(addr)
+}
+
+// nextNonce returns the first nonce after the executable transactions of an
+// account. The caller must hold the lock.
+func (p *PrivatePool) nextNonce(addr common.Address) uint64 {
+	nonce := p.state.GetNonce(addr)
+	for _, ptx := range p.accounts[addr] {
+		if ptx.tx.Nonce() != nonce {
+			break
+		}
+		nonce++
+	}
+	return nonce
+}
+
+// Stats returns the number of private transactions in the pool.
+func (p *PrivatePool) Stats() (int, int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.all), 0
+}
+
+// Content returns no transactions, the private ones are never exposed.
+func (p *PrivatePool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return make(map[common.Address][]*types.Transaction), make(map[common.Address][]*types.Transaction)
+}
+
+// ContentFrom returns no transactions, the private ones are never exposed.
+func (p *PrivatePool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
+	return nil, nil
+}
+
+// Locals returns no accounts, private transactions are never local.
+func (p *PrivatePool) Locals() []common.Address {
+	return nil
+}
+
+// Status returns unknown for every transaction, see Has.
+func (p *PrivatePool) Status(hash common.Hash) txpool.TxStatus {
+	return txpool.TxStatusUnknown
+}
+
+// findNonce returns the transaction with the given nonce, if any.
+func findNonce(txs []*privateTx, nonce uint64) *privateTx {
+	for _, ptx := range txs {
+		if ptx.tx.Nonce() == nonce {
+			return ptx
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package privatepool
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivatePool_NotExposed(t *testing.T) {
+	pool, key := newTestPool(t, nil)
+
+	tx := newTestTx(t, key, 0)
+	require.NoError(t, pool.AddPrivate(tx, 0, "alice"))
+	require.True(t, pool.SentBy(tx.Hash(), "alice"))
+	require.False(t, pool.SentBy(tx.Hash(), "bob"))
+
+	// the transaction is hidden from every public lookup
+	require.False(t, pool.Has(tx.Hash()))
+	require.Nil(t, pool.Get(tx.Hash()))
+	require.Equal(t, txpool.TxStatusUnknown, pool.Status(tx.Hash()))
+	require.Empty(t, pool.Pending(txpool.PendingFilter{OnlyPlainTxs: true}))
+	require.Equal(t, uint64(0), pool.Nonce(crypto.PubkeyToAddress(key.PublicKey)))
+
+	pending, _ := pool.Content()
+	require.Empty(t, pending)
+
+	// only the block building paths that request them see the transactions
+	private := pool.Pending(txpool.PendingFilter{OnlyPlainTxs: true, IncludePrivateTxs: true})
+	require.Len(t, private, 1)
+	require.Equal(t, tx.Hash(), private[crypto.PubkeyToAddress(key.PublicKey)][0].Hash)
+
+	// public submissions are never routed to the pool
+	require.False(t, pool.Filter(tx))
+	require.ErrorIs(t, pool.Add([]*types.Transaction{tx}, false, false)[0], ErrPublicSubmission)
+}
+
+func TestPrivatePool_Reset(t *testing.T) {
+	pool, key := newTestPool(t, nil)
+
+	included := newTestTx(t, key, 0)
+	expiring := newTestTx(t, key, 1)
+	kept := newTestTx(t, key, 2)
+
+	require.NoError(t, pool.AddPrivate(included, 0, ""))
+	require.NoError(t, pool.AddPrivate(expiring, 11, ""))
+	require.NoError(t, pool.AddPrivate(kept, 0, ""))
+
+	require.ErrorIs(t, pool.AddPrivate(newTestTx(t, key, 3), 10, ""), ErrTxExpired)
+
+	// the first transaction lands on-chain in block 11
+	pool.state.SetNonce(crypto.PubkeyToAddress(key.PublicKey), 1)
+	pool.Reset(nil, &types.Header{Number: big.NewInt(11), GasLimit: 30_000_000})
+
+	count, _ := pool.Stats()
+	require.Equal(t, 1, count)
+
+	// the remaining transaction is not executable because of the nonce gap
+	require.Empty(t, pool.Pending(txpool.PendingFilter{IncludePrivateTxs: true}))
+}
+
+func TestPrivatePool_Cancel(t *testing.T) {
+	reserved := make(map[common.Address]bool)
+	pool, key := newTestPool(t, func(addr common.Address, reserve bool) error {
+		if reserve && reserved[addr] {
+			return errors.New("address already reserved")
+		}
+		reserved[addr] = reserve
+		return nil
+	})
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	tx := newTestTx(t, key, 0)
+	require.NoError(t, pool.AddPrivate(tx, 0, ""))
+	require.True(t, reserved[addr])
+
+	require.NoError(t, pool.Cancel(tx.Hash()))
+	require.False(t, reserved[addr])
+	require.Empty(t, pool.Pending(txpool.PendingFilter{IncludePrivateTxs: true}))
+
+	require.ErrorIs(t, pool.Cancel(tx.Hash()), ErrTxNotFound)
+
+	// an address used by another pool cannot send private transactions
+	reserved[addr] = true
+	require.ErrorIs(t, pool.AddPrivate(tx, 0, ""), ErrAlreadyReservedAddr)
+}
+
+type testChain struct {
+	statedb *state.StateDB
+}
+
+func (c *testChain) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
+func (c *testChain) StateAt(common.Hash) (*state.StateDB, error) {
+	return c.statedb, nil
+}
+
+func newTestPool(t *testing.T, reserve txpool.AddressReserver) (*PrivatePool, *ecdsa.PrivateKey) {
+	key, _ := crypto.GenerateKey()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	if reserve == nil {
+		reserve = func(common.Address, bool) error { return nil }
+	}
+	pool := New(DefaultConfig, &testChain{statedb: statedb})
+	require.NoError(t, pool.Init(0, &types.Header{Number: big.NewInt(10), GasLimit: 30_000_000}, reserve))
+	t.Cleanup(func() { pool.Close() })
+
+	return pool, key
+}
+
+func newTestTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x1}, big.NewInt(1), params.TxGas, big.NewInt(1), nil), types.LatestSigner(params.TestChainConfig), key)
+	require.NoError(t, err)
+	return tx
+}
@@ -83,6 +83,9 @@ type PendingFilter struct {
 
 	OnlyPlainTxs bool // Return only plain EVM transactions (peer-join announces, block space filling)
 	OnlyBlobTxs  bool // Return only blob transactions (block blob-space filling)
+
+	// --- SUAVE SPECIFIC ---
+	IncludePrivateTxs bool // Return also the private transactions (builder block space filling, never announced)
 }
 
 // SubPool represents a specialized transaction pool that lives on its own (e.g.
@@ -11,19 +11,55 @@ import (
 // Simplified Share Bundle Type for PoC
 
 type SBundle struct {
-	BlockNumber     *big.Int      `json:"blockNumber,omitempty"` // if BlockNumber is set it must match DecryptionCondition!
-	MaxBlock        *big.Int      `json:"maxBlock,omitempty"`
-	Txs             Transactions  `json:"txs"`
-	RevertingHashes []common.Hash `json:"revertingHashes,omitempty"`
-	RefundPercent   *int          `json:"percent,omitempty"`
+	BlockNumber     *big.Int           `json:"blockNumber,omitempty"` // if BlockNumber is set it must match DecryptionCondition!
+	MaxBlock        *big.Int           `json:"maxBlock,omitempty"`
+	Txs             Transactions       `json:"txs"`
+	RevertingHashes []common.Hash      `json:"revertingHashes,omitempty"`
+	RefundPercent   *int               `json:"percent,omitempty"`
+	Constraints     *BundleConstraints `json:"constraints,omitempty"`
 }
 
 type RpcSBundle struct {
-	BlockNumber     *hexutil.Big    `json:"blockNumber,omitempty"`
-	MaxBlock        *hexutil.Big    `json:"maxBlock,omitempty"`
-	Txs             []hexutil.Bytes `json:"txs"`
-	RevertingHashes []common.Hash   `json:"revertingHashes,omitempty"`
-	RefundPercent   *int            `json:"percent,omitempty"`
+	BlockNumber     *hexutil.Big       `json:"blockNumber,omitempty"`
+	MaxBlock        *hexutil.Big       `json:"maxBlock,omitempty"`
+	Txs             []hexutil.Bytes    `json:"txs"`
+	RevertingHashes []common.Hash      `json:"revertingHashes,omitempty"`
+	RefundPercent   *int               `json:"percent,omitempty"`
+	Constraints     *BundleConstraints `json:"constraints,omitempty"`
+}
+
+// BundleConstraints are the conditions under which a bundle can be included,
+// on top of its block range. Every field is optional.
+type BundleConstraints struct {
+	MinTimestamp uint64       `json:"minTimestamp,omitempty"`
+	MaxTimestamp uint64       `json:"maxTimestamp,omitempty"`
+	TopOfBlock   bool         `json:"topOfBlock,omitempty"` // the bundle must be the first item of the block
+	After        *common.Hash `json:"after,omitempty"`      // the bundle must directly follow this transaction
+
+	// minimum value paid to the fee recipient per gas used by the bundle
+	MinCoinbasePaymentPerGas *hexutil.Big `json:"minCoinbasePaymentPerGas,omitempty"`
+
+	// conditions on the state right before the bundle, all of them must hold
+	State []*StatePredicate `json:"state,omitempty"`
+}
+
+// The comparison operators of the state predicates.
+const (
+	PredicateEq  = "eq"
+	PredicateNeq = "neq"
+	PredicateLt  = "lt"
+	PredicateLte = "lte"
+	PredicateGt  = "gt"
+	PredicateGte = "gte"
+)
+
+// StatePredicate compares the balance of an account, or one of its storage
+// slots read as an unsigned integer, with a value.
+type StatePredicate struct {
+	Address common.Address `json:"address"`
+	Slot    *common.Hash   `json:"slot,omitempty"` // the balance is compared if nil
+	Op      string         `json:"op"`
+	Value   *hexutil.Big   `json:"value"`
 }
 
 func (s *SBundle) MarshalJSON() ([]byte, error) {
@@ -47,6 +83,7 @@ func (s *SBundle) MarshalJSON() ([]byte, error) {
 		Txs:             txs,
 		RevertingHashes: s.RevertingHashes,
 		RefundPercent:   s.RefundPercent,
+		Constraints:     s.Constraints,
 	})
 }
 
@@ -72,6 +109,7 @@ func (s *SBundle) UnmarshalJSON(data []byte) error {
 	s.Txs = txs
 	s.RevertingHashes = rpcSBundle.RevertingHashes
 	s.RefundPercent = rpcSBundle.RefundPercent
+	s.Constraints = rpcSBundle.Constraints
 
 	return nil
 }
@@ -1,6 +1,11 @@
 package types
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
 
 // Structs
 
@@ -18,3 +23,22 @@ type BuildBlockArgs struct {
 	BeaconRoot            common.Hash
 	FillPending           bool
 }
+
+// BlockProfit is the value of a built block for its fee recipient, withdrawals
+// excluded.
+type BlockProfit struct {
+	PriorityFees      *hexutil.Big `json:"priorityFees"`      // priority fees of the transactions paid to the fee recipient
+	CoinbaseTransfers *hexutil.Big `json:"coinbaseTransfers"` // value explicitly transferred to the fee recipient
+}
+
+// Total returns the total value of the block for its fee recipient.
+func (p *BlockProfit) Total() *big.Int {
+	total := new(big.Int)
+	if p.PriorityFees != nil {
+		total.Add(total, p.PriorityFees.ToInt())
+	}
+	if p.CoinbaseTransfers != nil {
+		total.Add(total, p.CoinbaseTransfers.ToInt())
+	}
+	return total
+}
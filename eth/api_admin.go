@@ -24,6 +24,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -141,3 +142,25 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// --- SUAVE SPECIFIC ---
+
+// ReloadDenyList reloads the addresses that the built blocks must not touch
+// from the configured deny list file.
+func (api *AdminAPI) ReloadDenyList() (bool, error) {
+	if err := api.eth.Miner().DenyList().Reload(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetDenyList replaces the addresses that the built blocks must not touch.
+func (api *AdminAPI) SetDenyList(addrs []common.Address) bool {
+	api.eth.Miner().DenyList().Set(addrs)
+	return true
+}
+
+// DenyList returns the addresses that the built blocks must not touch.
+func (api *AdminAPI) DenyList() []common.Address {
+	return api.eth.Miner().DenyList().Addresses()
+}
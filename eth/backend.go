@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/txpool/privatepool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -60,6 +61,7 @@ import (
 	"github.com/ethereum/go-ethereum/suave/backends"
 	suave_builder "github.com/ethereum/go-ethereum/suave/builder"
 	suave_builder_api "github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/ethereum/go-ethereum/suave/bundlestore"
 )
 
 // Config contains the configuration options of the ETH protocol.
@@ -94,6 +96,9 @@ type Ethereum struct {
 	miner    *miner.Miner
 	gasPrice *big.Int
 
+	bundleStore *bundlestore.BundleStore
+	privatePool *privatepool.PrivatePool
+
 	networkID     uint64
 	netRPCService *ethapi.NetAPI
 
@@ -243,7 +248,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	// --- SUAVE SPECIFIC ---
+	eth.privatePool = privatepool.New(privatepool.DefaultConfig, eth.blockchain)
+
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool, eth.privatePool})
 	if err != nil {
 		return nil, err
 	}
@@ -266,6 +274,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, config.Miner, eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
+	denyList, err := miner.NewDenyList(config.Miner.DenyList)
+	if err != nil {
+		return nil, err
+	}
+	eth.miner.SetDenyList(denyList)
+
+	eth.bundleStore = bundlestore.New(bundlestore.DefaultConfig, eth.blockchain)
+	eth.miner.SetBundleSource(eth.bundleStore)
+
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
@@ -329,7 +346,13 @@ func (s *Ethereum) APIs() []rpc.API {
 		Service:   backends.NewEthBackendServer(s.APIBackend),
 	})
 
-	sessionManager := suave_builder.NewSessionManager(s.blockchain, s.txPool, &suave_builder.Config{})
+	sessionManager := suave_builder.NewSessionManager(s.blockchain, s.txPool, s.bundleStore, s.privatePool, &suave_builder.Config{
+		DenyList:           s.miner.DenyList(),
+		Admins:             s.config.Miner.SessionAdmins,
+		SessionBudget:      s.config.Miner.SessionBudget,
+		ClientBudget:       s.config.Miner.ClientBudget,
+		ClientBudgetWindow: s.config.Miner.ClientBudgetWindow,
+	})
 
 	apis = append(apis, rpc.API{
 		Namespace: "suavex",
@@ -425,6 +448,7 @@ func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
 	s.txPool.Close()
+	s.bundleStore.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
 
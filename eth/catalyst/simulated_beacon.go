@@ -20,6 +20,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -83,6 +84,9 @@ type SimulatedBeacon struct {
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
+
+	// --- SUAVE SPECIFIC ---
+	payloadSource PayloadSource
 }
 
 // NewSimulatedBeacon constructs a new simulated beacon chain.
@@ -158,13 +162,14 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 
 	var random [32]byte
 	rand.Read(random[:])
-	fcResponse, err := c.engineAPI.forkchoiceUpdated(c.curForkchoiceState, &engine.PayloadAttributes{
+	attrs := &engine.PayloadAttributes{
 		Timestamp:             timestamp,
 		SuggestedFeeRecipient: feeRecipient,
 		Withdrawals:           withdrawals,
 		Random:                random,
 		BeaconRoot:            &common.Hash{},
-	}, engine.PayloadV3, true)
+	}
+	fcResponse, err := c.engineAPI.forkchoiceUpdated(c.curForkchoiceState, attrs, engine.PayloadV3, true)
 	if err != nil {
 		return err
 	}
@@ -177,6 +182,14 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 	}
 	payload := envelope.ExecutionPayload
 
+	// --- SUAVE SPECIFIC ---
+	external := false
+	if c.payloadSource != nil {
+		if p := c.payloadSource(c.curForkchoiceState.HeadBlockHash, attrs); p != nil {
+			payload, external = p, true
+		}
+	}
+
 	var finalizedHash common.Hash
 	if payload.Number%devEpochLength == 0 {
 		finalizedHash = payload.BlockHash
@@ -190,7 +203,11 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 
 	// Independently calculate the blob hashes from sidecars.
 	blobHashes := make([]common.Hash, 0)
-	if envelope.BlobsBundle != nil {
+	if external {
+		if blobHashes, err = payloadBlobHashes(payload); err != nil {
+			return err
+		}
+	} else if envelope.BlobsBundle != nil {
 		hasher := sha256.New()
 		for _, commit := range envelope.BlobsBundle.Commitments {
 			var c kzg4844.Commitment
@@ -302,6 +319,33 @@ func (c *SimulatedBeacon) AdjustTime(adjustment time.Duration) error {
 	return c.sealBlock(withdrawals, parent.Time+uint64(adjustment))
 }
 
+// --- SUAVE SPECIFIC ---
+
+// PayloadSource provides the payload sealed by the simulated beacon on top of
+// the given parent with the given attributes, in place of the payload built by
+// the local node. A nil payload falls back to the local one.
+type PayloadSource func(parent common.Hash, attrs *engine.PayloadAttributes) *engine.ExecutableData
+
+// SetPayloadSource sets the source of the sealed payloads. It must be called
+// before the beacon is started.
+func (c *SimulatedBeacon) SetPayloadSource(source PayloadSource) {
+	c.payloadSource = source
+}
+
+// payloadBlobHashes returns the blob hashes referenced by the transactions of
+// the payload.
+func payloadBlobHashes(payload *engine.ExecutableData) ([]common.Hash, error) {
+	blobHashes := make([]common.Hash, 0)
+	for i, enc := range payload.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		blobHashes = append(blobHashes, tx.BlobHashes()...)
+	}
+	return blobHashes, nil
+}
+
 func RegisterSimulatedBeaconAPIs(stack *node.Node, sim *SimulatedBeacon) {
 	api := &api{sim}
 	if sim.period == 0 {
@@ -0,0 +1,195 @@
+// Package telemetry traces the requests with OpenTelemetry. The spans are
+// exported by the OpenTelemetry SDK to an OTLP/HTTP endpoint or to a file, and
+// the trace context is propagated with the W3C traceparent header.
+//
+// Nothing is recorded until a tracer is installed with SetTracer, the spans
+// started before are no-ops.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// scopeName is the instrumentation scope of the spans.
+const scopeName = "github.com/ethereum/go-ethereum"
+
+// DefaultServiceName is the service name of the spans if none is configured.
+const DefaultServiceName = "geth"
+
+// DefaultNamespaces are the RPC namespaces whose calls are traced if none are
+// configured.
+var DefaultNamespaces = []string{"suavex"}
+
+// Config contains the configuration of the span export.
+type Config struct {
+	Endpoint    string   `toml:",omitempty"` // URL of the OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces
+	File        string   `toml:",omitempty"` // file the spans are appended to, one JSON span per line
+	ServiceName string   `toml:",omitempty"` // name of the process in the traces
+	Namespaces  []string `toml:",omitempty"` // RPC namespaces whose calls are traced, DefaultNamespaces if empty
+}
+
+// Enabled returns whether the config has an exporter.
+func (c *Config) Enabled() bool {
+	return c.Endpoint != "" || c.File != ""
+}
+
+// Tracer records the spans with the OpenTelemetry SDK and exports them in
+// batches in the background.
+type Tracer struct {
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	namespaces map[string]bool
+	file       *os.File // output of the file exporter, if configured
+}
+
+// New creates a tracer exporting the spans as configured. The tracer records
+// the spans once installed with SetTracer.
+func New(cfg Config) (*Tracer, error) {
+	var (
+		exp  sdktrace.SpanExporter
+		file *os.File
+		err  error
+	)
+	switch {
+	case cfg.Endpoint != "" && cfg.File != "":
+		return nil, errors.New("telemetry: both an endpoint and a file are configured")
+	case cfg.Endpoint != "":
+		if exp, err = otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint)); err != nil {
+			return nil, fmt.Errorf("telemetry: %w", err)
+		}
+	case cfg.File != "":
+		if file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+			return nil, fmt.Errorf("telemetry: %w", err)
+		}
+		if exp, err = stdouttrace.New(stdouttrace.WithWriter(file)); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("telemetry: %w", err)
+		}
+	default:
+		return nil, errors.New("telemetry: no exporter configured")
+	}
+	t := NewWithProcessor(cfg, sdktrace.NewBatchSpanProcessor(exp))
+	t.file = file
+	return t, nil
+}
+
+// NewWithProcessor creates a tracer passing the ended spans to the given
+// processor instead of the configured exporter, e.g. to a span recorder in the
+// tests.
+func NewWithProcessor(cfg Config, processor sdktrace.SpanProcessor) *Tracer {
+	service := cfg.ServiceName
+	if service == "" {
+		service = DefaultServiceName
+	}
+	namespaces := cfg.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = DefaultNamespaces
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+	)
+
+	t := &Tracer{
+		provider:   provider,
+		tracer:     provider.Tracer(scopeName),
+		namespaces: make(map[string]bool, len(namespaces)),
+	}
+	for _, ns := range namespaces {
+		t.namespaces[ns] = true
+	}
+	return t
+}
+
+// Flush exports the ended spans.
+func (t *Tracer) Flush(ctx context.Context) error {
+	return t.provider.ForceFlush(ctx)
+}
+
+// Close exports the ended spans and shuts the exporter down. The spans ended
+// afterwards are dropped.
+func (t *Tracer) Close() error {
+	err := t.provider.Shutdown(context.Background())
+	if t.file != nil {
+		err = errors.Join(err, t.file.Close())
+	}
+	return err
+}
+
+// global is the tracer recording the spans, nil if tracing is disabled.
+var global atomic.Pointer[Tracer]
+
+// SetTracer installs the tracer recording the spans. A nil tracer disables
+// tracing.
+func SetTracer(t *Tracer) {
+	global.Store(t)
+}
+
+// Start starts a span of an internal operation, child of the span of the
+// context. The returned context carries the new span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, noop.Span{}
+	}
+	return t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer starts the span of a JSON-RPC call served by the process. Only
+// the calls of the configured namespaces are traced.
+func StartServer(ctx context.Context, method string) (context.Context, trace.Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, noop.Span{}
+	}
+	namespace, _, _ := strings.Cut(method, "_")
+	if !t.namespaces[namespace] {
+		return ctx, noop.Span{}
+	}
+	return t.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		semconv.RPCSystemKey.String("jsonrpc"),
+		semconv.RPCService(namespace),
+		semconv.RPCMethod(method),
+	))
+}
+
+// End ends the span, marking its operation as failed if err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// propagator carries the trace context in the W3C traceparent header.
+var propagator = propagation.TraceContext{}
+
+// Extract returns a context carrying the span of the caller of a request, if
+// its headers have a valid traceparent. The context is returned as is
+// otherwise.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject sets the traceparent header of an outgoing request to the span of the
+// context, if there is one.
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,168 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func withRecorder(t *testing.T, cfg Config) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewWithProcessor(cfg, recorder)
+	SetTracer(tracer)
+	t.Cleanup(func() {
+		SetTracer(nil)
+		tracer.Close()
+	})
+	return recorder
+}
+
+func TestDisabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "op")
+	if span.IsRecording() {
+		t.Fatal("span recorded without tracer")
+	}
+	if ctx != context.Background() {
+		t.Fatal("context changed without tracer")
+	}
+	End(span, errors.New("failed"))
+}
+
+func TestSpans(t *testing.T) {
+	recorder := withRecorder(t, Config{})
+
+	ctx, root := StartServer(context.Background(), "suavex_call")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("failed"))
+	End(root, nil)
+
+	// the calls of the other namespaces are not traced
+	_, span := StartServer(context.Background(), "eth_call")
+	if span.IsRecording() {
+		t.Error("eth_call traced with the default namespaces")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	c, r := spans[0], spans[1]
+	if c.Name() != "child" || r.Name() != "suavex_call" {
+		t.Fatalf("wrong spans: %s, %s", c.Name(), r.Name())
+	}
+	if c.Parent().SpanID() != r.SpanContext().SpanID() || c.SpanContext().TraceID() != r.SpanContext().TraceID() {
+		t.Error("child span not a child of the root")
+	}
+	if r.SpanKind() != trace.SpanKindServer || c.SpanKind() != trace.SpanKindInternal {
+		t.Errorf("wrong kinds: root %s, child %s", r.SpanKind(), c.SpanKind())
+	}
+	if c.Status().Code != codes.Error || c.Status().Description != "failed" {
+		t.Errorf("wrong child status: %+v", c.Status())
+	}
+	if r.Status().Code != codes.Unset {
+		t.Errorf("wrong root status: %+v", r.Status())
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	recorder := withRecorder(t, Config{Namespaces: []string{"eth"}})
+
+	for _, method := range []string{"suavex_call", "eth_call"} {
+		_, span := StartServer(context.Background(), method)
+		span.End()
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "eth_call" {
+		t.Fatalf("got %d spans, want eth_call only", len(spans))
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	recorder := withRecorder(t, Config{})
+
+	header := http.Header{}
+	header.Set("traceparent", traceparent)
+	ctx, span := StartServer(Extract(context.Background(), header), "suavex_call")
+
+	out := http.Header{}
+	Inject(ctx, out)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	sc := spans[0].SpanContext()
+	if sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || spans[0].Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("span not a child of the remote parent: %s", spans[0].Parent().SpanID())
+	}
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + sc.SpanID().String() + "-01"
+	if got := out.Get("traceparent"); got != want {
+		t.Errorf("injected %q, want %q", got, want)
+	}
+
+	// The spans of the unsampled requests are not recorded.
+	header.Set("traceparent", strings.TrimSuffix(traceparent, "01")+"00")
+	if _, span := StartServer(Extract(context.Background(), header), "suavex_call"); span.IsRecording() {
+		t.Error("span recorded for unsampled parent")
+	}
+}
+
+func TestExporters(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "spans.json")
+	for _, cfg := range []Config{{Endpoint: srv.URL + "/v1/traces"}, {File: file}} {
+		tracer, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		SetTracer(tracer)
+		_, span := Start(context.Background(), "op")
+		span.End()
+		SetTracer(nil)
+		if err := tracer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	if len(received) != 1 || received[0] != "/v1/traces" {
+		t.Errorf("endpoint received %v", received)
+	}
+	mu.Unlock()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"Name":"op"`) {
+		t.Errorf("file contains %q", data)
+	}
+
+	if _, err := New(Config{Endpoint: srv.URL, File: file}); err == nil {
+		t.Error("no error with two exporters")
+	}
+}
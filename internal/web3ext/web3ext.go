@@ -190,6 +190,15 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'reloadDenyList',
+			call: 'admin_reloadDenyList'
+		}),
+		new web3._extend.Method({
+			name: 'setDenyList',
+			call: 'admin_setDenyList',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -204,6 +213,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'denyList',
+			getter: 'admin_denyList'
+		}),
 	]
 });
 `
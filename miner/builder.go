@@ -1,10 +1,14 @@
 package miner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"runtime"
+	"sync"
+	"time"
 
 	denebBuilder "github.com/attestantio/go-builder-client/api/deneb"
 	builderV1 "github.com/attestantio/go-builder-client/api/v1"
@@ -16,21 +20,24 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
 	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/holiman/uint256"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
-	ErrInvalidInclusionRange = errors.New("invalid inclusion range")
-	ErrInvalidBlockNumber    = errors.New("invalid block number")
-	ErrExceedsMaxBlock       = errors.New("block number exceeds max block")
-	ErrEmptyTxs              = errors.New("empty transactions")
+	ErrInvalidInclusionRange = fmt.Errorf("%w: invalid inclusion range", suavextypes.ErrInvalidBundle)
+	ErrInvalidBlockNumber    = fmt.Errorf("%w: invalid block number", suavextypes.ErrInvalidBundle)
+	ErrExceedsMaxBlock       = fmt.Errorf("%w: block number exceeds max block", suavextypes.ErrInvalidBundle)
+	ErrEmptyTxs              = fmt.Errorf("%w: empty transactions", suavextypes.ErrInvalidBundle)
 )
 
 type BuilderConfig struct {
@@ -39,6 +46,7 @@ type BuilderConfig struct {
 	EthBackend  Backend
 	Chain       *core.BlockChain
 	GasCeil     uint64
+	DenyList    *DenyList
 }
 
 type BuilderArgs struct {
@@ -47,6 +55,11 @@ type BuilderArgs struct {
 	ProposerPubkey []byte
 	Extra          []byte
 	Slot           uint64
+	Timestamp      uint64
+	Random         common.Hash
+	Withdrawals    types.Withdrawals
+	BeaconRoot     *common.Hash
+	GasLimit       uint64 // derived from the parent and the gas ceil if zero
 }
 
 type Builder struct {
@@ -54,14 +67,16 @@ type Builder struct {
 	wrk   *Miner
 	args  *BuilderArgs
 	block *types.Block
+	base  *environment // environment before any transaction, to rebuild from
+
+	// bundleAccess tracks the state accessed by the bundles simulated in the builder
+	bundleAccess map[common.Hash]*accessTracer
+
+	usage *usageTracker // resources spent by the builder, shared by its environments
 }
 
 func NewBuilder(config *BuilderConfig, args *BuilderArgs) (*Builder, error) {
-	b := &Builder{
-		args: args,
-	}
-
-	b.wrk = &Miner{
+	wrk := &Miner{
 		config: &Config{
 			GasCeil: config.GasCeil,
 		},
@@ -69,13 +84,31 @@ func NewBuilder(config *BuilderConfig, args *BuilderArgs) (*Builder, error) {
 		engine:      config.Engine,
 		chain:       config.Chain,
 		txpool:      config.EthBackend.TxPool(),
+		denyList:    config.DenyList,
+	}
+	return newBuilder(wrk, args, false)
+}
+
+// newBuilder creates a builder on top of the given miner. If forceTime is set
+// the timestamp of the arguments must be valid for the parent block.
+func newBuilder(wrk *Miner, args *BuilderArgs, forceTime bool) (*Builder, error) {
+	b := &Builder{
+		wrk:          wrk,
+		args:         args,
+		bundleAccess: make(map[common.Hash]*accessTracer),
+		usage:        new(usageTracker),
 	}
 
 	workerParams := &generateParams{
-		parentHash: args.ParentHash,
-		forceTime:  false,
-		coinbase:   args.FeeRecipient,
-		extra:      args.Extra,
+		parentHash:  args.ParentHash,
+		timestamp:   args.Timestamp,
+		forceTime:   forceTime,
+		coinbase:    args.FeeRecipient,
+		random:      args.Random,
+		withdrawals: args.Withdrawals,
+		beaconRoot:  args.BeaconRoot,
+		extra:       args.Extra,
+		gasLimit:    args.GasLimit,
 	}
 	env, err := b.wrk.prepareWork(workerParams)
 	if err != nil {
@@ -83,61 +116,175 @@ func NewBuilder(config *BuilderConfig, args *BuilderArgs) (*Builder, error) {
 	}
 
 	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	env.usage = b.usage
 	b.env = env
+	b.base = env.copy()
 
 	return b, nil
 }
 
-func (b *Builder) addTransaction(txn *types.Transaction, env *environment) (*suavextypes.SimulateTransactionResult, error) {
+// NewBuilderFromState creates a builder for the block described by the header on
+// top of the given state, without a backing chain. Such a builder can add and
+// simulate transactions and bundles but cannot seal blocks, and the BLOCKHASH
+// opcode returns zero in it.
+func NewBuilderFromState(chainConfig *params.ChainConfig, header *types.Header, statedb *state.StateDB) *Builder {
+	header = types.CopyHeader(header)
+	header.GasUsed = 0
+
+	env := &environment{
+		signer:   types.MakeSigner(chainConfig, header.Number, header.Time),
+		state:    statedb,
+		coinbase: header.Coinbase,
+		header:   header,
+		gasPool:  new(core.GasPool).AddGas(header.GasLimit),
+		usage:    new(usageTracker),
+	}
+	return &Builder{
+		args: &BuilderArgs{
+			ParentHash:   header.ParentHash,
+			FeeRecipient: header.Coinbase,
+		},
+		wrk: &Miner{
+			config: &Config{
+				GasCeil: header.GasLimit,
+			},
+			chainConfig: chainConfig,
+		},
+		env:          env,
+		base:         env.copy(),
+		bundleAccess: make(map[common.Hash]*accessTracer),
+		usage:        env.usage,
+	}
+}
+
+// chainContext returns the chain the transactions are applied on. Builders
+// created from a detached state have none.
+func (miner *Miner) chainContext() core.ChainContext {
+	if miner.chain == nil {
+		return &ChainContextDummy{}
+	}
+	if miner.built != nil {
+		return miner.built
+	}
+	return miner.chain
+}
+
+func (b *Builder) addTransaction(txn *types.Transaction, env *environment) (*suavextypes.SimulateTransactionResult, *accessTracer, error) {
 	// If the context is not set, the logs will not be recorded
-	b.env.state.SetTxContext(txn.Hash(), b.env.tcount)
+	env.state.SetTxContext(txn.Hash(), env.tcount)
+
+	tracer := newAccessTracer()
+	env.tracer = tracer.Hooks()
+	env.state.SetLogger(env.tracer)
+	defer func() {
+		env.tracer = nil
+		env.state.SetLogger(nil)
+	}()
 
 	prevGas := env.header.GasUsed
 	logs, err := b.wrk.commitTransactionWithLogs(env, txn)
+	markSimulation("tx", err)
 	if err != nil {
 		return &suavextypes.SimulateTransactionResult{
-			Error:   err.Error(),
-			Success: false,
-		}, err
+			Error:     err.Error(),
+			ErrorCode: suavextypes.ErrorCode(err),
+			Success:   false,
+		}, nil, err
 	}
 	egp := env.header.GasUsed - prevGas
-	return receiptToSimResult(&types.Receipt{Logs: logs}, egp), nil
+
+	result := receiptToSimResult(&types.Receipt{Logs: logs}, egp)
+	result.Reads = tracer.reads.toStateAccess()
+	result.Writes = tracer.writes.toStateAccess()
+	return result, tracer, nil
+}
+
+// AddTransaction applies the transaction to the builder state. A failing
+// transaction is reported in the result, ErrBuildInterrupted is returned if
+// the context is done before the transaction is applied.
+func (b *Builder) AddTransaction(ctx context.Context, txn *types.Transaction) (*suavextypes.SimulateTransactionResult, error) {
+	defer b.bindContext(ctx, "AddTransaction")()
+
+	res, _, err := b.addTransaction(txn, b.env)
+	if errors.Is(err, ErrBuildInterrupted) {
+		return res, err
+	}
+	return res, nil
 }
 
-func (b *Builder) AddTransaction(txn *types.Transaction) (*suavextypes.SimulateTransactionResult, error) {
-	res, _ := b.addTransaction(txn, b.env)
+// SimulateTransaction applies the transaction and returns the result, the
+// builder state is reverted afterwards.
+func (b *Builder) SimulateTransaction(ctx context.Context, txn *types.Transaction) (*suavextypes.SimulateTransactionResult, error) {
+	defer b.bindContext(ctx, "SimulateTransaction")()
+
+	cp := b.checkpoint(b.env)
+	defer cp.revert()
+
+	res, _, err := b.addTransaction(txn, b.env)
+	if errors.Is(err, ErrBuildInterrupted) {
+		return res, err
+	}
 	return res, nil
 }
 
-func (b *Builder) AddTransactions(txns types.Transactions) ([]*suavextypes.SimulateTransactionResult, error) {
+func (b *Builder) AddTransactions(ctx context.Context, txns types.Transactions) ([]*suavextypes.SimulateTransactionResult, error) {
+	defer b.bindContext(ctx, "AddTransactions")()
+
 	results := make([]*suavextypes.SimulateTransactionResult, 0)
-	snap := b.env.copy()
+	cp := b.checkpoint(b.env)
 
 	for _, txn := range txns {
-		res, err := b.addTransaction(txn, snap)
+		res, _, err := b.addTransaction(txn, b.env)
 		results = append(results, res)
+		if errors.Is(err, ErrBuildInterrupted) {
+			cp.revert()
+			return results, err
+		}
 		if err != nil {
+			cp.revert()
 			return results, nil
 		}
 	}
-	b.env = snap
+	cp.discard()
 	return results, nil
 }
 
-func (b *Builder) addBundle(bundle *suavextypes.Bundle, env *environment) (*suavextypes.SimulateBundleResult, error) {
+func (b *Builder) addBundle(bundle *suavextypes.Bundle, env *environment) (*suavextypes.SimulateBundleResult, *accessTracer, error) {
+	result, tracer, err := b.applyBundle(bundle, env)
+	markSimulation("bundle", err)
+	return result, tracer, err
+}
+
+func (b *Builder) applyBundle(bundle *suavextypes.Bundle, env *environment) (*suavextypes.SimulateBundleResult, *accessTracer, error) {
 	if err := checkBundleParams(b.env.header.Number, bundle); err != nil {
 		return &suavextypes.SimulateBundleResult{
-			Error:   err.Error(),
-			Success: false,
-		}, err
+			Hash:      bundle.Hash(),
+			Error:     err.Error(),
+			ErrorCode: suavextypes.ErrorCode(err),
+			Success:   false,
+		}, nil, err
+	}
+
+	if err := checkConstraints(bundle.Constraints, env); err != nil {
+		return &suavextypes.SimulateBundleResult{
+			Hash:             bundle.Hash(),
+			Error:            err.Error(),
+			ErrorCode:        suavextypes.ErrorCode(err),
+			FailedConstraint: failedConstraint(err),
+			Success:          false,
+		}, nil, err
 	}
 
+	env.usage.addBundle()
 	revertingHashes := bundle.RevertingHashesMap()
 	egp := uint64(0)
+	bundleTracer := newAccessTracer()
+	start := len(env.txs)
+	balancePre := env.state.GetBalance(env.coinbase).ToBig()
 
 	var results []*suavextypes.SimulateTransactionResult
 	for _, txn := range bundle.Txs {
-		result, err := b.addTransaction(txn, env)
+		result, tracer, err := b.addTransaction(txn, env)
 		results = append(results, result)
 		if err != nil {
 			if _, ok := revertingHashes[txn.Hash()]; ok {
@@ -145,37 +292,180 @@ func (b *Builder) addBundle(bundle *suavextypes.Bundle, env *environment) (*suav
 				continue
 			}
 			return &suavextypes.SimulateBundleResult{
+				Hash:                       bundle.Hash(),
 				Error:                      err.Error(),
+				ErrorCode:                  suavextypes.ErrorCode(err),
 				SimulateTransactionResults: results,
 				Success:                    false,
-			}, err
+			}, nil, err
 		}
 		egp += result.Egp
+		bundleTracer.merge(tracer)
+	}
+	payment := new(big.Int).Sub(env.state.GetBalance(env.coinbase).ToBig(), balancePre)
+	if err := checkCoinbasePayment(bundle.Constraints, payment, egp); err != nil {
+		return &suavextypes.SimulateBundleResult{
+			Hash:                       bundle.Hash(),
+			Error:                      err.Error(),
+			ErrorCode:                  suavextypes.ErrorCode(err),
+			FailedConstraint:           failedConstraint(err),
+			SimulateTransactionResults: results,
+			Success:                    false,
+		}, nil, err
 	}
+	env.bundles = append(env.bundles, bundleSpan{start: start, end: len(env.txs), bundle: bundle})
 
 	return &suavextypes.SimulateBundleResult{
+		Hash:                       bundle.Hash(),
 		Egp:                        egp,
 		SimulateTransactionResults: results,
 		Success:                    true,
-	}, nil
+		Reads:                      bundleTracer.reads.toStateAccess(),
+		Writes:                     bundleTracer.writes.toStateAccess(),
+	}, bundleTracer, nil
 }
 
-func (b *Builder) AddBundles(bundles []*suavextypes.Bundle) ([]*suavextypes.SimulateBundleResult, error) {
+func (b *Builder) AddBundles(ctx context.Context, bundles []*suavextypes.Bundle) ([]*suavextypes.SimulateBundleResult, error) {
+	defer b.bindContext(ctx, "AddBundles")()
+
 	var results []*suavextypes.SimulateBundleResult
-	snap := b.env.copy()
+	cp := b.checkpoint(b.env)
 
 	for _, bundle := range bundles {
-		result, err := b.addBundle(bundle, snap)
+		result, tracer, err := b.addBundle(bundle, b.env)
 		results = append(results, result)
+		if errors.Is(err, ErrBuildInterrupted) {
+			cp.revert()
+			return results, err
+		}
 		if err != nil {
+			cp.revert()
 			return results, nil
 		}
+		b.bundleAccess[result.Hash] = tracer
 	}
 
-	b.env = snap
+	cp.discard()
+	return results, nil
+}
+
+// SimulateBundles simulates every bundle independently on top of the current
+// environment. The simulations run concurrently on copies of the environment,
+// so the builder state is left unchanged. If the context is done, the results
+// are returned with ErrBuildInterrupted and the interrupted simulations fail.
+func (b *Builder) SimulateBundles(ctx context.Context, bundles []*suavextypes.Bundle) ([]*suavextypes.SimulateBundleResult, error) {
+	defer b.bindContext(ctx, "SimulateBundles")()
+
+	results, tracers := b.simulateBundles(bundles, b.env)
+	for i, tracer := range tracers {
+		if tracer != nil {
+			b.bundleAccess[results[i].Hash] = tracer
+		}
+	}
+	if ctx.Err() != nil {
+		return results, interruptedError(ctx)
+	}
 	return results, nil
 }
 
+// SimulateBundle applies the bundle and returns the result, the builder state
+// is reverted afterwards.
+func (b *Builder) SimulateBundle(ctx context.Context, bundle *suavextypes.Bundle) (*suavextypes.SimulateBundleResult, error) {
+	defer b.bindContext(ctx, "SimulateBundle")()
+
+	cp := b.checkpoint(b.env)
+	result, tracer := b.simulateBundle(bundle, b.env)
+	cp.revert()
+	if tracer != nil {
+		b.bundleAccess[result.Hash] = tracer
+	}
+	if ctx.Err() != nil {
+		return result, interruptedError(ctx)
+	}
+	return result, nil
+}
+
+func (b *Builder) simulateBundles(bundles []*suavextypes.Bundle, env *environment) ([]*suavextypes.SimulateBundleResult, []*accessTracer) {
+	var (
+		results = make([]*suavextypes.SimulateBundleResult, len(bundles))
+		tracers = make([]*accessTracer, len(bundles))
+		sem     = make(chan struct{}, runtime.NumCPU())
+		copyMu  sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for i, bundle := range bundles {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, bundle *suavextypes.Bundle) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			copyMu.Lock()
+			cpy := env.copy()
+			copyMu.Unlock()
+
+			results[i], tracers[i] = b.simulateBundle(bundle, cpy)
+		}(i, bundle)
+	}
+	wg.Wait()
+
+	return results, tracers
+}
+
+func (b *Builder) simulateBundle(bundle *suavextypes.Bundle, env *environment) (*suavextypes.SimulateBundleResult, *accessTracer) {
+	balancePre := env.state.GetBalance(env.coinbase)
+	result, tracer, _ := b.addBundle(bundle, env)
+	balancePost := env.state.GetBalance(env.coinbase)
+
+	result.CoinbaseProfit = new(big.Int).Sub(balancePost.ToBig(), balancePre.ToBig())
+	return result, tracer
+}
+
+// CheckBundleConflict reports whether two bundles previously simulated in the
+// builder access overlapping state, that is, one of them writes an account or
+// a storage slot read or written by the other one.
+func (b *Builder) CheckBundleConflict(bundleA, bundleB common.Hash) (*suavextypes.BundleConflict, error) {
+	a, ok := b.bundleAccess[bundleA]
+	if !ok {
+		return nil, fmt.Errorf("%w: bundle %s not simulated", suavextypes.ErrInvalidParams, bundleA)
+	}
+	other, ok := b.bundleAccess[bundleB]
+	if !ok {
+		return nil, fmt.Errorf("%w: bundle %s not simulated", suavextypes.ErrInvalidParams, bundleB)
+	}
+
+	conflicts := a.conflicts(other)
+	if conflicts.empty() {
+		return &suavextypes.BundleConflict{Conflict: false}, nil
+	}
+	return &suavextypes.BundleConflict{
+		Conflict: true,
+		State:    conflicts.toStateAccess(),
+	}, nil
+}
+
+// BlockNumber returns the number of the block being built.
+func (b *Builder) BlockNumber() *big.Int {
+	return new(big.Int).Set(b.env.header.Number)
+}
+
+func (b *Builder) ParentHash() common.Hash {
+	return b.env.header.ParentHash
+}
+
+// Transactions returns the transactions applied to the builder state so far.
+func (b *Builder) Transactions() types.Transactions {
+	return b.env.txs
+}
+
+// State returns the state of the builder. The state must not be modified.
+func (b *Builder) State() *state.StateDB {
+	return b.env.state
+}
+
 func (b *Builder) GetBalance(addr common.Address) *big.Int {
 	return b.env.state.GetBalance(addr).ToBig()
 }
@@ -193,10 +483,17 @@ func (c *ChainContextDummy) GetHeader(common.Hash, uint64) *types.Header {
 	return nil
 }
 
-func (b *Builder) Call(args *ethapi.TransactionArgs) ([]byte, error) {
-	// TODO: Figure out the timeout situation
+// Call executes the call on top of the state of the builder and reverts its
+// changes. The gas of the call is capped by gasCap and by the gas limit of the
+// block, its gas and execution time count in the usage of the builder. The
+// execution is aborted when the context is done.
+func (b *Builder) Call(ctx context.Context, args *ethapi.TransactionArgs, gasCap uint64) ([]byte, error) {
+	defer b.bindContext(ctx, "Call")()
 
-	error := args.CallDefaults(0, common.Big0, b.wrk.chainConfig.ChainID)
+	if gasCap == 0 || gasCap > b.env.header.GasLimit {
+		gasCap = b.env.header.GasLimit
+	}
+	error := args.CallDefaults(gasCap, common.Big0, b.wrk.chainConfig.ChainID)
 	if error != nil {
 		return nil, error
 	}
@@ -205,40 +502,43 @@ func (b *Builder) Call(args *ethapi.TransactionArgs) ([]byte, error) {
 	blockContext := core.NewEVMBlockContext(b.env.header, &ChainContextDummy{}, &common.MaxAddress)
 	txContext := core.NewEVMTxContext(msg)
 
-	state_copy := b.env.state.Copy()
-	evm := vm.NewEVM(blockContext, txContext, state_copy, b.wrk.chainConfig, vm.Config{NoBaseFee: true})
+	// the call is not finalised, its changes are reverted with the journal
+	statedb := b.env.state
+	snap := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snap)
+
+	evm := vm.NewEVM(blockContext, txContext, statedb, b.wrk.chainConfig, vm.Config{NoBaseFee: true})
+	stop := context.AfterFunc(ctx, evm.Cancel)
+	defer stop()
 
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
+	start := time.Now()
 	result, err := core.ApplyMessage(evm, msg, gp)
+	if result != nil {
+		b.usage.addCall(result.UsedGas, time.Since(start))
+	}
 	if err != nil {
 		return nil, err
 	}
-	if err := state_copy.Error(); err != nil {
+	if err := statedb.Error(); err != nil {
 		return nil, err
 	}
 
 	if evm.Cancelled() {
-		return nil, fmt.Errorf("execution aborted")
-	}
-
-	if err != nil {
-		return result.ReturnData, fmt.Errorf("err: %w (supplied gas %d)", err, msg.GasLimit)
+		return nil, interruptedError(ctx)
 	}
 	return result.ReturnData, nil
 }
 
-func (b *Builder) FillPending() error {
-	if err := b.wrk.commitPendingTxs(b.env); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (b *Builder) BuildBlock() (*types.Block, error) {
+func (b *Builder) BuildBlock(ctx context.Context) (*types.Block, error) {
+	defer buildBlockTimer.UpdateSince(time.Now())
+	defer b.bindContext(ctx, "BuildBlock")()
 	work := b.env
 
-	body := types.Body{Transactions: work.txs, Withdrawals: nil}
-	block, err := b.wrk.engine.FinalizeAndAssemble(b.wrk.chain, work.header, work.state, &body, work.receipts)
+	span := work.startSpan("miner.finalizeAndAssemble", attribute.Int("txs", len(work.txs)))
+	body := types.Body{Transactions: work.txs, Withdrawals: b.args.Withdrawals}
+	block, err := b.wrk.engine.FinalizeAndAssemble(b.wrk.headerReader(), work.header, work.state, &body, work.receipts)
+	telemetry.End(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +550,7 @@ func (b *Builder) Bid(builderPubKey phase0.BLSPubKey) (*suavextypes.SubmitBlockR
 	work := b.env
 
 	if b.block == nil {
-		return nil, fmt.Errorf("block not built")
+		return nil, ErrBlockNotBuilt
 	}
 
 	envelope := engine.BlockToExecutableData(b.block, totalFees(b.block, work.receipts), work.sidecars)
@@ -295,6 +595,7 @@ func (b *Builder) Bid(builderPubKey phase0.BLSPubKey) (*suavextypes.SubmitBlockR
 			BlobsBundle:      &denebBuilder.BlobsBundle{},
 		},
 	}
+	markBid(envelope.BlockValue)
 	return &bidRequest, nil
 }
 
@@ -335,6 +636,14 @@ func executableDataToDenebExecutionPayload(data *engine.ExecutableData) (*deneb.
 		return nil, errors.New("base fee per gas: overflow")
 	}
 
+	var blobGasUsed, excessBlobGas uint64
+	if data.BlobGasUsed != nil {
+		blobGasUsed = *data.BlobGasUsed
+	}
+	if data.ExcessBlobGas != nil {
+		excessBlobGas = *data.ExcessBlobGas
+	}
+
 	return &deneb.ExecutionPayload{
 		ParentHash:    [32]byte(data.ParentHash),
 		FeeRecipient:  [20]byte(data.FeeRecipient),
@@ -351,6 +660,8 @@ func executableDataToDenebExecutionPayload(data *engine.ExecutableData) (*deneb.
 		BlockHash:     [32]byte(data.BlockHash),
 		Transactions:  transactionData,
 		Withdrawals:   withdrawalData,
+		BlobGasUsed:   blobGasUsed,
+		ExcessBlobGas: excessBlobGas,
 	}, nil
 }
 
@@ -381,7 +692,7 @@ func checkBundleParams(currentBlockNumber *big.Int, bundle *suavextypes.Bundle)
 		return ErrEmptyTxs
 	}
 
-	return nil
+	return validateConstraints(bundle.Constraints)
 }
 
 func (miner *Miner) commitTransactionWithLogs(env *environment, tx *types.Transaction) ([]*types.Log, error) {
@@ -394,6 +705,9 @@ func (miner *Miner) commitTransactionWithLogs(env *environment, tx *types.Transa
 
 // copy creates a deep copy of environment.
 func (env *environment) copy() *environment {
+	span := env.startSpan("miner.copyState")
+	defer span.End()
+
 	cpy := &environment{
 		signer:   env.signer,
 		state:    env.state.Copy(),
@@ -412,6 +726,15 @@ func (env *environment) copy() *environment {
 	cpy.sidecars = make([]*types.BlobTxSidecar, len(env.sidecars))
 	copy(cpy.sidecars, env.sidecars)
 
+	cpy.ctx = env.ctx
+	cpy.bundles = append([]bundleSpan(nil), env.bundles...)
+	cpy.usage = env.usage
+	cpy.denied = env.denied
+	env.usage.addStateCopy()
+	if env.profit != nil {
+		cpy.profit = env.profit.copy()
+	}
+
 	return cpy
 }
 
@@ -0,0 +1,127 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+// ErrBlockNotBuilt is returned when chaining on a builder that has not built a block yet.
+var ErrBlockNotBuilt = suavextypes.ErrBlockNotBuilt
+
+// builtChain extends the chain with blocks built by builders but not imported,
+// so that the next block can be built on top of them. The built blocks are
+// assumed to become canonical.
+type builtChain struct {
+	*core.BlockChain
+	blocks []*types.Block // built blocks, the first one extends the chain
+	state  *state.StateDB // post-state of the last built block, never modified
+}
+
+// extend returns the chain extended with the given block and its post-state.
+func (c *builtChain) extend(chain *core.BlockChain, block *types.Block, statedb *state.StateDB) *builtChain {
+	next := &builtChain{
+		BlockChain: chain,
+		blocks:     []*types.Block{block},
+		state:      statedb.Copy(),
+	}
+	if c != nil {
+		next.blocks = append(append([]*types.Block{}, c.blocks...), block)
+	}
+	return next
+}
+
+// head returns the header of the last built block.
+func (c *builtChain) head() *types.Header {
+	return c.blocks[len(c.blocks)-1].Header()
+}
+
+func (c *builtChain) built(hash common.Hash) *types.Block {
+	for _, block := range c.blocks {
+		if block.Hash() == hash {
+			return block
+		}
+	}
+	return nil
+}
+
+func (c *builtChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if block := c.built(hash); block != nil && block.NumberU64() == number {
+		return block.Header()
+	}
+	return c.BlockChain.GetHeader(hash, number)
+}
+
+func (c *builtChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	if block := c.built(hash); block != nil {
+		return block.Header()
+	}
+	return c.BlockChain.GetHeaderByHash(hash)
+}
+
+func (c *builtChain) GetHeaderByNumber(number uint64) *types.Header {
+	for _, block := range c.blocks {
+		if block.NumberU64() == number {
+			return block.Header()
+		}
+	}
+	return c.BlockChain.GetHeaderByNumber(number)
+}
+
+func (c *builtChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	for i, block := range c.blocks {
+		if block.Hash() != hash || block.NumberU64() != number {
+			continue
+		}
+		first := c.blocks[0]
+		td := c.BlockChain.GetTd(first.ParentHash(), first.NumberU64()-1)
+		if td == nil {
+			return nil
+		}
+		td = new(big.Int).Set(td)
+		for _, block := range c.blocks[:i+1] {
+			td.Add(td, block.Difficulty())
+		}
+		return td
+	}
+	return c.BlockChain.GetTd(hash, number)
+}
+
+// headerReader returns the chain the headers of the block are prepared and
+// finalised with.
+func (miner *Miner) headerReader() consensus.ChainHeaderReader {
+	if miner.built != nil {
+		return miner.built
+	}
+	return miner.chain
+}
+
+// NewChild creates a builder for the block following the last one built by the
+// builder, on top of its post-state. The built block does not need to be part
+// of the chain. The child is not affected by the later changes of the builder.
+func (b *Builder) NewChild(args *BuilderArgs) (*Builder, error) {
+	if b.block == nil {
+		return nil, ErrBlockNotBuilt
+	}
+	if b.wrk.chain == nil {
+		return nil, errors.New("builder without chain")
+	}
+	wrk := &Miner{
+		config:      b.wrk.config,
+		chainConfig: b.wrk.chainConfig,
+		engine:      b.wrk.engine,
+		chain:       b.wrk.chain,
+		txpool:      b.wrk.txpool,
+		denyList:    b.wrk.denyList,
+		built:       b.wrk.built.extend(b.wrk.chain, b.block, b.env.state),
+	}
+	cpy := *args
+	cpy.ParentHash = b.block.Hash()
+	return newBuilder(wrk, &cpy, false)
+}
@@ -0,0 +1,98 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// envCheckpoint is a point an environment can be reverted to. Unlike a copy of
+// the environment, it costs nothing upfront and reverting it costs in
+// proportion to the changes made since.
+type envCheckpoint struct {
+	env *environment
+
+	// The state is reverted with a checkpoint of its journal, or restored from
+	// a copy before Byzantium, as the state root computed after every
+	// transaction can't be reverted.
+	state int
+	copy  *environment
+
+	tcount      int
+	txs         int
+	sidecars    int
+	blobs       int
+	bundles     int
+	gasUsed     uint64
+	blobGasUsed *uint64
+	gasPool     *core.GasPool
+	transfers   *big.Int
+}
+
+// checkpoint returns a point the environment can be reverted to. The
+// checkpoint is taken between transactions, and must be reverted or discarded
+// before the ones taken earlier.
+func (b *Builder) checkpoint(env *environment) *envCheckpoint {
+	if !b.wrk.chainConfig.IsByzantium(env.header.Number) {
+		return &envCheckpoint{env: env, copy: env.copy()}
+	}
+	cp := &envCheckpoint{
+		env:      env,
+		state:    env.state.Checkpoint(),
+		tcount:   env.tcount,
+		txs:      len(env.txs),
+		sidecars: len(env.sidecars),
+		blobs:    env.blobs,
+		bundles:  len(env.bundles),
+		gasUsed:  env.header.GasUsed,
+	}
+	if env.header.BlobGasUsed != nil {
+		blobGasUsed := *env.header.BlobGasUsed
+		cp.blobGasUsed = &blobGasUsed
+	}
+	if env.gasPool != nil {
+		gasPool := *env.gasPool
+		cp.gasPool = &gasPool
+	}
+	if env.profit != nil {
+		cp.transfers = new(big.Int).Set(env.profit.transfers)
+	}
+	return cp
+}
+
+// revert undoes the changes made to the environment since the checkpoint.
+func (cp *envCheckpoint) revert() {
+	env := cp.env
+	if cp.copy != nil {
+		// the context is bound to the environment, not to its contents
+		ctx := env.ctx
+		*env = *cp.copy
+		env.ctx = ctx
+		return
+	}
+	env.state.RevertToCheckpoint(cp.state)
+
+	// The slices are capped so that the transactions applied next don't
+	// overwrite the ones seen by the holders of the previous slices.
+	env.tcount = cp.tcount
+	env.txs = env.txs[:cp.txs:cp.txs]
+	env.receipts = env.receipts[:cp.txs:cp.txs]
+	env.sidecars = env.sidecars[:cp.sidecars:cp.sidecars]
+	env.blobs = cp.blobs
+	env.bundles = env.bundles[:cp.bundles:cp.bundles]
+	env.header.GasUsed = cp.gasUsed
+	if cp.blobGasUsed != nil {
+		*env.header.BlobGasUsed = *cp.blobGasUsed
+	}
+	env.gasPool = cp.gasPool
+	if env.profit != nil {
+		env.profit.transfers.Set(cp.transfers)
+	}
+}
+
+// discard keeps the changes made to the environment since the checkpoint.
+func (cp *envCheckpoint) discard() {
+	if cp.copy == nil {
+		cp.env.state.DiscardCheckpoint(cp.state)
+	}
+}
@@ -0,0 +1,143 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+var (
+	ErrConstraintNotMet   = suavextypes.ErrConstraintNotMet
+	ErrInvalidConstraints = fmt.Errorf("%w: invalid constraints", suavextypes.ErrInvalidBundle)
+	errUnknownPredicateOp = errors.New("unknown predicate operator")
+)
+
+// Names of the bundle constraints, reported when one is not met.
+const (
+	constraintTimestamp       = "timestamp"
+	constraintTopOfBlock      = "topOfBlock"
+	constraintAfter           = "after"
+	constraintCoinbasePayment = "minCoinbasePaymentPerGas"
+	constraintState           = "state"
+)
+
+// constraintError is a bundle constraint that is not met.
+type constraintError struct {
+	constraint string
+	reason     string
+}
+
+func (e *constraintError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrConstraintNotMet, e.constraint, e.reason)
+}
+
+func (e *constraintError) Unwrap() error {
+	return ErrConstraintNotMet
+}
+
+// failedConstraint returns the name of the constraint the error is about, if any.
+func failedConstraint(err error) string {
+	var cerr *constraintError
+	if errors.As(err, &cerr) {
+		return cerr.constraint
+	}
+	return ""
+}
+
+// validateConstraints checks that the constraints can be met at all.
+func validateConstraints(c *types.BundleConstraints) error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxTimestamp != 0 && c.MinTimestamp > c.MaxTimestamp {
+		return fmt.Errorf("%w: min timestamp %d after max timestamp %d", ErrInvalidConstraints, c.MinTimestamp, c.MaxTimestamp)
+	}
+	if c.TopOfBlock && c.After != nil {
+		return fmt.Errorf("%w: top of block bundle cannot follow a transaction", ErrInvalidConstraints)
+	}
+	for i, p := range c.State {
+		if p.Value == nil {
+			return fmt.Errorf("%w: state %d without value", ErrInvalidConstraints, i)
+		}
+		if _, err := compare(p.Op, 0); err != nil {
+			return fmt.Errorf("%w: state %d: %w", ErrInvalidConstraints, i, err)
+		}
+	}
+	return nil
+}
+
+// checkConstraints checks the constraints that apply before the bundle, against
+// the environment it is applied to.
+func checkConstraints(c *types.BundleConstraints, env *environment) error {
+	if c == nil {
+		return nil
+	}
+	if c.MinTimestamp != 0 && env.header.Time < c.MinTimestamp {
+		return &constraintError{constraintTimestamp, fmt.Sprintf("block timestamp %d before %d", env.header.Time, c.MinTimestamp)}
+	}
+	if c.MaxTimestamp != 0 && env.header.Time > c.MaxTimestamp {
+		return &constraintError{constraintTimestamp, fmt.Sprintf("block timestamp %d after %d", env.header.Time, c.MaxTimestamp)}
+	}
+	if c.TopOfBlock && len(env.txs) != 0 {
+		return &constraintError{constraintTopOfBlock, fmt.Sprintf("%d transactions before the bundle", len(env.txs))}
+	}
+	if c.After != nil {
+		if len(env.txs) == 0 {
+			return &constraintError{constraintAfter, "no transaction before the bundle"}
+		}
+		if last := env.txs[len(env.txs)-1].Hash(); last != *c.After {
+			return &constraintError{constraintAfter, fmt.Sprintf("bundle follows %s instead of %s", last, *c.After)}
+		}
+	}
+	for i, p := range c.State {
+		var value *big.Int
+		if p.Slot == nil {
+			value = env.state.GetBalance(p.Address).ToBig()
+		} else {
+			value = env.state.GetState(p.Address, *p.Slot).Big()
+		}
+		ok, err := compare(p.Op, value.Cmp(p.Value.ToInt()))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &constraintError{fmt.Sprintf("%s[%d]", constraintState, i), fmt.Sprintf("%s is not %s %s", value, p.Op, p.Value.ToInt())}
+		}
+	}
+	return nil
+}
+
+// checkCoinbasePayment checks the payment of the applied bundle to the fee
+// recipient against the constraints.
+func checkCoinbasePayment(c *types.BundleConstraints, payment *big.Int, gasUsed uint64) error {
+	if c == nil || c.MinCoinbasePaymentPerGas == nil {
+		return nil
+	}
+	floor := new(big.Int).Mul(c.MinCoinbasePaymentPerGas.ToInt(), new(big.Int).SetUint64(gasUsed))
+	if payment.Cmp(floor) < 0 {
+		return &constraintError{constraintCoinbasePayment, fmt.Sprintf("payment %s for %d gas below %s per gas", payment, gasUsed, c.MinCoinbasePaymentPerGas.ToInt())}
+	}
+	return nil
+}
+
+// compare returns whether the result of a comparison satisfies the operator.
+func compare(op string, cmp int) (bool, error) {
+	switch op {
+	case types.PredicateEq:
+		return cmp == 0, nil
+	case types.PredicateNeq:
+		return cmp != 0, nil
+	case types.PredicateLt:
+		return cmp < 0, nil
+	case types.PredicateLte:
+		return cmp <= 0, nil
+	case types.PredicateGt:
+		return cmp > 0, nil
+	case types.PredicateGte:
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("%w %q", errUnknownPredicateOp, op)
+}
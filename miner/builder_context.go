@@ -0,0 +1,88 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// ErrBuildInterrupted is returned when the context of a request is done before
+// the block building work completes. The error wraps the one of the context,
+// so context.Canceled and context.DeadlineExceeded can be told apart.
+var ErrBuildInterrupted = suavextypes.ErrBuildInterrupted
+
+func interruptedError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrBuildInterrupted, context.Cause(ctx))
+}
+
+// bindContext aborts the execution of the transactions on the builder state,
+// and on the copies made from it, once the context is done. The work of the
+// call is traced in a span named after the method of the builder. The binding
+// ends when the returned function is called.
+func (b *Builder) bindContext(ctx context.Context, method string) func() {
+	ctx, span := telemetry.Start(ctx, "miner.Builder."+method)
+	b.env.ctx = ctx
+	return func() {
+		b.env.ctx = nil
+		span.End()
+	}
+}
+
+// startSpan starts a span of the work on the environment, child of the span of
+// the request bound to it. Nothing is traced outside of a request.
+func (env *environment) startSpan(name string, attrs ...attribute.KeyValue) trace.Span {
+	if env.ctx == nil {
+		return noop.Span{}
+	}
+	_, span := telemetry.Start(env.ctx, name, attrs...)
+	return span
+}
+
+// applyTransactionWithContext applies the transaction like core.ApplyTransaction
+// and cancels the EVM once the context of the environment is done. A cancelled
+// execution is aborted before its state changes are finalised, so that they
+// can be reverted.
+func (miner *Miner) applyTransactionWithContext(env *environment, tx *types.Transaction, hooks *tracing.Hooks) (*types.Receipt, error) {
+	if env.ctx == nil {
+		return core.ApplyTransaction(miner.chainConfig, miner.chainContext(), &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, vm.Config{Tracer: hooks})
+	}
+	if env.ctx.Err() != nil {
+		return nil, interruptedError(env.ctx)
+	}
+	msg, err := core.TransactionToMessage(tx, types.MakeSigner(miner.chainConfig, env.header.Number, env.header.Time), env.header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+
+	var evm *vm.EVM
+	cancelHooks := new(tracing.Hooks)
+	if hooks != nil {
+		*cancelHooks = *hooks
+	}
+	onExit := cancelHooks.OnExit
+	cancelHooks.OnExit = func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+		if onExit != nil {
+			onExit(depth, output, gasUsed, err, reverted)
+		}
+		// the cancelled EVM stops at the next jump as if the code ended there
+		if depth == 0 && evm.Cancelled() {
+			panic(executionAbort{interruptedError(env.ctx)})
+		}
+	}
+
+	blockContext := core.NewEVMBlockContext(env.header, miner.chainContext(), &env.coinbase)
+	evm = vm.NewEVM(blockContext, core.NewEVMTxContext(msg), env.state, miner.chainConfig, vm.Config{Tracer: cancelHooks})
+
+	stop := context.AfterFunc(env.ctx, evm.Cancel)
+	defer stop()
+	return core.ApplyTransactionWithEVM(msg, miner.chainConfig, env.gasPool, env.state, env.header.Number, env.header.Hash(), tx, &env.header.GasUsed, evm)
+}
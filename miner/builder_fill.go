@@ -0,0 +1,132 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/holiman/uint256"
+)
+
+// FillPending fills the builder state with the pending transactions of the pool,
+// private ones included, following the given options. A nil opts fills the
+// remaining block space with the plain transactions paying the configured tip.
+// If the deadline is reached or the context is done, the transactions applied
+// so far are kept.
+func (b *Builder) FillPending(ctx context.Context, opts *suavextypes.FillPendingOpts) (*suavextypes.FillPendingResult, error) {
+	if opts == nil {
+		opts = &suavextypes.FillPendingOpts{}
+	}
+	tip := opts.MinTip
+	if tip == nil {
+		b.wrk.confMu.RLock()
+		tip = b.wrk.config.GasPrice
+		b.wrk.confMu.RUnlock()
+	} else if tip.Sign() < 0 || tip.BitLen() > 256 {
+		return nil, fmt.Errorf("%w: invalid min tip %s", suavextypes.ErrInvalidParams, tip)
+	}
+
+	defer b.bindContext(ctx, "FillPending")()
+	env := b.env
+
+	filter := txpool.PendingFilter{
+		MinTip:            uint256.MustFromBig(tip),
+		IncludePrivateTxs: true,
+	}
+	if env.header.BaseFee != nil {
+		filter.BaseFee = uint256.MustFromBig(env.header.BaseFee)
+	}
+	if env.header.ExcessBlobGas != nil {
+		filter.BlobFee = uint256.MustFromBig(eip4844.CalcBlobFee(*env.header.ExcessBlobGas))
+	}
+	filter.OnlyPlainTxs, filter.OnlyBlobTxs = true, false
+	plainTxs := excludePending(b.wrk.txpool.Pending(filter), opts)
+
+	blobTxs := make(map[common.Address][]*txpool.LazyTransaction)
+	if opts.IncludeBlobTxs {
+		filter.OnlyPlainTxs, filter.OnlyBlobTxs = false, true
+		blobTxs = excludePending(b.wrk.txpool.Pending(filter), opts)
+	}
+
+	// keep the reserved gas out of the pool while filling the block
+	if err := env.gasPool.SubGas(opts.ReservedGas); err != nil {
+		return nil, fmt.Errorf("%w: reserved gas %d exceeds the available gas %d", suavextypes.ErrInvalidParams, opts.ReservedGas, env.gasPool.Gas())
+	}
+	defer env.gasPool.AddGas(opts.ReservedGas)
+
+	if opts.MaxTxs != 0 {
+		env.maxTxs = env.tcount + int(opts.MaxTxs)
+		defer func() { env.maxTxs = 0 }()
+	}
+
+	interrupt := new(atomic.Int32)
+	stop := context.AfterFunc(ctx, func() {
+		interrupt.Store(commitInterruptTimeout)
+	})
+	defer stop()
+	if opts.Timeout != 0 {
+		timer := time.AfterFunc(time.Duration(opts.Timeout)*time.Millisecond, func() {
+			interrupt.Store(commitInterruptTimeout)
+		})
+		defer timer.Stop()
+	}
+
+	res := &suavextypes.FillPendingResult{
+		Txs: []common.Hash{},
+	}
+	start, gasUsed := len(env.txs), env.gasPool.Gas()
+
+	err := b.wrk.commitTransactions(env, newTransactionsByPriceAndNonce(env.signer, plainTxs, env.header.BaseFee), newTransactionsByPriceAndNonce(env.signer, blobTxs, env.header.BaseFee), interrupt)
+	if errors.Is(err, errBlockInterruptedByTimeout) || errors.Is(err, ErrBuildInterrupted) {
+		res.DeadlineExceeded = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range env.txs[start:] {
+		res.Txs = append(res.Txs, tx.Hash())
+	}
+	res.GasUsed = gasUsed - env.gasPool.Gas()
+	return res, nil
+}
+
+// excludePending removes the transactions of the excluded senders from the pending
+// set, together with the ones sent to the excluded recipients and every later
+// transaction of the same account, which cannot be executed anymore.
+func excludePending(pending map[common.Address][]*txpool.LazyTransaction, opts *suavextypes.FillPendingOpts) map[common.Address][]*txpool.LazyTransaction {
+	for _, addr := range opts.ExcludedSenders {
+		delete(pending, addr)
+	}
+	if len(opts.ExcludedRecipients) == 0 {
+		return pending
+	}
+
+	excluded := make(map[common.Address]struct{}, len(opts.ExcludedRecipients))
+	for _, addr := range opts.ExcludedRecipients {
+		excluded[addr] = struct{}{}
+	}
+	for addr, txs := range pending {
+		for i, ltx := range txs {
+			tx := ltx.Resolve()
+			if tx == nil || tx.To() == nil {
+				continue
+			}
+			if _, ok := excluded[*tx.To()]; ok {
+				txs = txs[:i]
+				break
+			}
+		}
+		if len(txs) == 0 {
+			delete(pending, addr)
+		} else {
+			pending[addr] = txs
+		}
+	}
+	return pending
+}
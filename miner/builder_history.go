@@ -0,0 +1,91 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+// ErrInvalidTxIndex is returned when a block is replayed up to an index past
+// its transactions.
+var ErrInvalidTxIndex = fmt.Errorf("%w: invalid transaction index", suavextypes.ErrInvalidParams)
+
+// BuilderArgsFromBlock returns the arguments of a builder for the given block,
+// with the header fields of the block.
+func BuilderArgsFromBlock(block *types.Block) *BuilderArgs {
+	return &BuilderArgs{
+		ParentHash:   block.ParentHash(),
+		FeeRecipient: block.Coinbase(),
+		Extra:        block.Extra(),
+		Timestamp:    block.Time(),
+		Random:       block.MixDigest(),
+		Withdrawals:  block.Withdrawals(),
+		BeaconRoot:   block.BeaconRoot(),
+		GasLimit:     block.GasLimit(),
+	}
+}
+
+// NewBuilderFromBlock creates a builder for the given block of the chain on top of
+// its parent, and re-applies the transactions of the block before txIndex. The
+// state of the parent must be available, which requires an archive node for the
+// old blocks.
+func NewBuilderFromBlock(ctx context.Context, config *BuilderConfig, block *types.Block, txIndex int) (*Builder, []*suavextypes.SimulateTransactionResult, error) {
+	if txIndex < 0 || txIndex > len(block.Transactions()) {
+		return nil, nil, fmt.Errorf("%w: %d, block has %d transactions", ErrInvalidTxIndex, txIndex, len(block.Transactions()))
+	}
+	if config.Chain != nil && !config.Chain.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
+		return nil, nil, fmt.Errorf("state of block %s %w", block.ParentHash(), suavextypes.ErrUnavailable.WithData(&suavextypes.ResourceErrorData{Resource: "state"}))
+	}
+
+	wrk := &Miner{
+		config: &Config{
+			GasCeil: config.GasCeil,
+		},
+		chainConfig: config.ChainConfig,
+		engine:      config.Engine,
+		chain:       config.Chain,
+		txpool:      config.EthBackend.TxPool(),
+		denyList:    config.DenyList,
+	}
+	b, err := newBuilder(wrk, BuilderArgsFromBlock(block), true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer b.bindContext(ctx, "replay")()
+	results := make([]*suavextypes.SimulateTransactionResult, 0, txIndex)
+	for i, tx := range block.Transactions()[:txIndex] {
+		res, err := b.replayTransaction(tx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transaction %d (%s): %w", i, tx.Hash(), err)
+		}
+		results = append(results, res)
+	}
+	return b, results, nil
+}
+
+// replayTransaction applies a transaction of a chain block. The blobs of the
+// blob transactions are not kept by the chain, these are applied with an empty
+// sidecar.
+func (b *Builder) replayTransaction(tx *types.Transaction) (*suavextypes.SimulateTransactionResult, error) {
+	if tx.Type() != types.BlobTxType || tx.BlobTxSidecar() != nil {
+		res, _, err := b.addTransaction(tx, b.env)
+		return res, err
+	}
+
+	env := b.env
+	env.state.SetTxContext(tx.Hash(), env.tcount)
+	prevGas := env.header.GasUsed
+	receipt, err := b.wrk.applyTransaction(env, tx)
+	if err != nil {
+		return nil, err
+	}
+	env.txs = append(env.txs, tx)
+	env.receipts = append(env.receipts, receipt)
+	env.sidecars = append(env.sidecars, &types.BlobTxSidecar{})
+	*env.header.BlobGasUsed += receipt.BlobGasUsed
+	env.tcount++
+	return receiptToSimResult(receipt, env.header.GasUsed-prevGas), nil
+}
@@ -2,119 +2,102 @@ package miner
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/params"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
 )
 
 // SUAVE
 
-func (miner *Miner) rawCommitTransactions(env *environment, txs types.Transactions) error {
-	gasLimit := env.header.GasLimit
-	if env.gasPool == nil {
-		env.gasPool = new(core.GasPool).AddGas(gasLimit)
-	}
-
-	// TODO: logs should be a part of the env and returned to whoever requested the block
-	// var coalescedLogs []*types.Log
-
-	for _, tx := range txs {
-		// If we don't have enough gas for any further transactions then we're done.
-		if env.gasPool.Gas() < params.TxGas {
-			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
-			break
-		}
-		// Error may be ignored here. The error has already been checked
-		// during transaction acceptance is the transaction pool.
-		from, _ := types.Sender(env.signer, tx)
-
-		// Check whether the tx is replay protected. If we're not in the EIP155 hf
-		// phase, start ignoring the sender until we do.
-		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
-			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", miner.chainConfig.EIP155Block)
+// BundleSource provides the stored bundles eligible for inclusion in a block.
+type BundleSource interface {
+	Eligible(blockNumber *big.Int) []*suavextypes.Bundle
+}
 
-			return fmt.Errorf("invalid reply protected tx %s", tx.Hash())
-		}
+// newLegacyBuilder creates a builder on top of the miner for the block building
+// requests of the SUAVE chain, so they are executed the same way as sessions.
+// The value of the block for the fee recipient of the request is accounted for
+// while the transactions are applied.
+func (miner *Miner) newLegacyBuilder(args *types.BuildBlockArgs, coinbase common.Address) (*Builder, error) {
+	beaconRoot := args.BeaconRoot
+	b, err := newBuilder(miner, &BuilderArgs{
+		ParentHash:     args.Parent,
+		FeeRecipient:   coinbase,
+		ProposerPubkey: args.ProposerPubkey,
+		Extra:          args.Extra,
+		Slot:           args.Slot,
+		Timestamp:      args.Timestamp,
+		Random:         args.Random,
+		Withdrawals:    args.Withdrawals,
+		BeaconRoot:     &beaconRoot,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	b.env.profit = newProfitTracker(args.FeeRecipient)
+	return b, nil
+}
 
-		// Start executing the transaction
-		env.state.SetTxContext(tx.Hash(), env.tcount)
-
-		// logs, err := w.commitTransaction(env, tx)
-		err := miner.commitTransaction(env, tx)
-
-		switch {
-		case errors.Is(err, core.ErrNonceTooLow):
-			log.Debug("Skipping transaction with low nonce", "hash", tx.Hash(), "sender", from, "nonce", tx.Nonce())
-			return err
-		case errors.Is(err, nil):
-			// coalescedLogs = append(coalescedLogs, logs...)
-			env.tcount++
-		default:
-			// Transaction is regarded as invalid, drop all consecutive transactions from
-			// the same sender because of `nonce-too-high` clause.
-			log.Debug("Transaction failed, account skipped", "hash", tx.Hash(), "err", err)
-			return err
+// addLegacyTransactions applies the transactions to the builder and fails if
+// any of them fails.
+func (b *Builder) addLegacyTransactions(ctx context.Context, txs types.Transactions) error {
+	results, err := b.AddTransactions(ctx, txs)
+	if err != nil {
+		return err
+	}
+	for i, res := range results {
+		if !res.Success {
+			return fmt.Errorf("transaction %s failed: %s", txs[i].Hash(), res.Error)
 		}
 	}
 	return nil
 }
 
-func (miner *Miner) commitPendingTxs(work *environment) error {
-	if err := miner.fillTransactions(nil, work); err != nil {
-		return err
+// fillLegacyPending fills the block with the pending transactions of the pool,
+// including the private and blob ones. The block is left partially filled if
+// the context is done.
+func (b *Builder) fillLegacyPending(ctx context.Context) error {
+	_, err := b.FillPending(ctx, &suavextypes.FillPendingOpts{IncludeBlobTxs: true})
+	return err
+}
+
+// buildLegacyBlock seals the block of the builder.
+func (b *Builder) buildLegacyBlock(ctx context.Context) (*types.Block, []*types.BlobTxSidecar, error) {
+	block, err := b.BuildBlock(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
-	return nil
+	return block, envSidecars(b.env), nil
 }
 
-func (miner *Miner) buildBlockFromTxs(ctx context.Context, args *types.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, []*types.BlobTxSidecar, error) {
-	params := &generateParams{
-		timestamp:   args.Timestamp,
-		forceTime:   true,
-		parentHash:  args.Parent,
-		coinbase:    args.FeeRecipient,
-		random:      args.Random,
-		extra:       args.Extra,
-		withdrawals: args.Withdrawals,
-		beaconRoot:  &args.BeaconRoot,
-		// noUncle:     true,
-		noTxs: false,
-	}
-
-	work, err := miner.prepareWork(params)
+func (miner *Miner) buildBlockFromTxs(ctx context.Context, args *types.BuildBlockArgs, txs types.Transactions) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error) {
+	b, err := miner.newLegacyBuilder(args, args.FeeRecipient)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	profitPre := work.state.GetBalance(args.FeeRecipient)
-
-	if err := miner.rawCommitTransactions(work, txs); err != nil {
+	if err := b.addLegacyTransactions(ctx, txs); err != nil {
 		return nil, nil, nil, err
 	}
 	if args.FillPending {
-		if err := miner.commitPendingTxs(work); err != nil {
+		if err := b.fillLegacyPending(ctx); err != nil {
 			return nil, nil, nil, err
 		}
 	}
 
-	profitPost := work.state.GetBalance(args.FeeRecipient)
-	// TODO : Is it okay to set Uncle List to nil?
-	body := types.Body{Transactions: work.txs, Withdrawals: params.withdrawals}
-	sidecars := envSidecars(work)
-	block, err := miner.engine.FinalizeAndAssemble(miner.chain, work.header, work.state, &body, work.receipts)
+	block, sidecars, err := b.buildLegacyBlock(ctx)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	blockProfit := new(big.Int).Sub(profitPost.ToBig(), profitPre.ToBig())
-	return block, blockProfit, sidecars, nil
+	return block, b.env.blockProfit(), sidecars, nil
 }
 
-func (miner *Miner) buildBlockFromBundles(ctx context.Context, args *types.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, []*types.BlobTxSidecar, error) {
+func (miner *Miner) buildBlockFromBundles(ctx context.Context, args *types.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error) {
 	// create ephemeral addr and private key for payment txn
 	ephemeralPrivKey, err := crypto.GenerateKey()
 	if err != nil {
@@ -122,38 +105,39 @@ func (miner *Miner) buildBlockFromBundles(ctx context.Context, args *types.Build
 	}
 	ephemeralAddr := crypto.PubkeyToAddress(ephemeralPrivKey.PublicKey)
 
-	params := &generateParams{
-		timestamp:   args.Timestamp,
-		forceTime:   true,
-		parentHash:  args.Parent,
-		coinbase:    ephemeralAddr, // NOTE : overriding BuildBlockArgs.FeeRecipient TODO : make customizable
-		random:      args.Random,
-		extra:       args.Extra,
-		withdrawals: args.Withdrawals,
-		beaconRoot:  &args.BeaconRoot,
-		// noUncle:     true,
-		noTxs: false,
-	}
-
-	work, err := miner.prepareWork(params)
+	// NOTE : overriding BuildBlockArgs.FeeRecipient TODO : make customizable
+	b, err := miner.newLegacyBuilder(args, ephemeralAddr)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	baseFee := b.env.header.BaseFee
 
 	// Assume static 28000 gas transfers for both mev-share and proposer payments
-	refundTransferCost := new(big.Int).Mul(big.NewInt(28000), work.header.BaseFee)
+	refundTransferCost := new(big.Int).Mul(big.NewInt(28000), baseFee)
 
-	profitPre := work.state.GetBalance(params.coinbase)
+	profitPre := b.GetBalance(ephemeralAddr)
 
-	for _, bundle := range bundles {
+	for _, sbundle := range bundles {
 		// NOTE: failing bundles will cause the block to not be built!
+		bundle := &suavextypes.Bundle{
+			BlockNumber:     sbundle.BlockNumber,
+			MaxBlock:        sbundle.MaxBlock,
+			Txs:             sbundle.Txs,
+			RevertingHashes: sbundle.RevertingHashes,
+			RefundPercent:   sbundle.RefundPercent,
+			Constraints:     sbundle.Constraints,
+		}
 
 		// apply bundle
-		profitPreBundle := work.state.GetBalance(params.coinbase)
-		if err := miner.rawCommitTransactions(work, bundle.Txs); err != nil {
+		profitPreBundle := b.GetBalance(ephemeralAddr)
+		results, err := b.AddBundles(ctx, []*suavextypes.Bundle{bundle})
+		if err != nil {
 			return nil, nil, nil, err
 		}
-		profitPostBundle := work.state.GetBalance(params.coinbase)
+		if res := results[0]; !res.Success {
+			return nil, nil, nil, fmt.Errorf("bundle %s failed: %s", res.Hash, res.Error)
+		}
+		profitPostBundle := b.GetBalance(ephemeralAddr)
 
 		// calc & refund user if bundle has multiple txns and wants refund
 		if len(bundle.Txs) > 1 && bundle.RefundPercent != nil {
@@ -163,12 +147,12 @@ func (miner *Miner) buildBlockFromBundles(ctx context.Context, args *types.Build
 				// default refund
 				refundPrct = 10
 			}
-			bundleProfit := new(big.Int).Sub(profitPostBundle.ToBig(), profitPreBundle.ToBig())
+			bundleProfit := new(big.Int).Sub(profitPostBundle, profitPreBundle)
 			refundAmt := new(big.Int).Div(bundleProfit, big.NewInt(int64(refundPrct)))
 			// subtract payment txn transfer costs
 			refundAmt = new(big.Int).Sub(refundAmt, refundTransferCost)
 
-			currNonce := work.state.GetNonce(ephemeralAddr)
+			currNonce := b.env.state.GetNonce(ephemeralAddr)
 			// HACK to include payment txn
 			// multi refund block untested
 			userTx := bundle.Txs[0] // NOTE : assumes first txn is refund recipient
@@ -181,56 +165,60 @@ func (miner *Miner) buildBlockFromBundles(ctx context.Context, args *types.Build
 				To:       &refundAddr,
 				Value:    refundAmt,
 				Gas:      28000,
-				GasPrice: work.header.BaseFee,
-			}), work.signer, ephemeralPrivKey)
+				GasPrice: baseFee,
+			}), b.env.signer, ephemeralPrivKey)
 
 			if err != nil {
 				return nil, nil, nil, err
 			}
 
 			// commit payment txn
-			if err := miner.rawCommitTransactions(work, types.Transactions{paymentTx}); err != nil {
+			if err := b.addLegacyTransactions(ctx, types.Transactions{paymentTx}); err != nil {
 				return nil, nil, nil, err
 			}
 		}
 	}
+	if miner.bundles != nil {
+		// the stored bundles are best effort, the failing ones are dropped
+		if _, err := b.MergeBundles(ctx, miner.bundles.Eligible(b.BlockNumber()), nil); err != nil {
+			return nil, nil, nil, err
+		}
+	}
 	if args.FillPending {
-		if err := miner.commitPendingTxs(work); err != nil {
+		if err := b.fillLegacyPending(ctx); err != nil {
 			return nil, nil, nil, err
 		}
 	}
 
-	profitPost := work.state.GetBalance(params.coinbase)
-	proposerProfit := new(big.Int).Set(profitPost.ToBig()) // = post-pre-transfer_cost
-	proposerProfit = proposerProfit.Sub(profitPost.ToBig(), profitPre.ToBig())
+	profitPost := b.GetBalance(ephemeralAddr)
+	proposerProfit := new(big.Int).Sub(profitPost, profitPre) // = post-pre-transfer_cost
 	proposerProfit = proposerProfit.Sub(proposerProfit, refundTransferCost)
 
-	currNonce := work.state.GetNonce(ephemeralAddr)
+	currNonce := b.env.state.GetNonce(ephemeralAddr)
 	paymentTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
 		Nonce:    currNonce,
 		To:       &args.FeeRecipient,
 		Value:    proposerProfit,
 		Gas:      28000,
-		GasPrice: work.header.BaseFee,
-	}), work.signer, ephemeralPrivKey)
+		GasPrice: baseFee,
+	}), b.env.signer, ephemeralPrivKey)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("could not sign proposer payment: %w", err)
 	}
 
 	// commit payment txn
-	if err := miner.rawCommitTransactions(work, types.Transactions{paymentTx}); err != nil {
-		return nil, nil, nil, fmt.Errorf("could not sign proposer payment: %w", err)
+	if err := b.addLegacyTransactions(ctx, types.Transactions{paymentTx}); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not commit proposer payment: %w", err)
 	}
 
-	log.Info("buildBlockFromBundles", "num_bundles", len(bundles), "num_txns", len(work.txs), "profit", proposerProfit)
-	// TODO : Is it okay to set Uncle List to nil?
-	body := types.Body{Transactions: work.txs, Withdrawals: params.withdrawals}
-	sidecars := envSidecars(work)
-	block, err := miner.engine.FinalizeAndAssemble(miner.chain, work.header, work.state, &body, work.receipts)
+	// the payment to the fee recipient is accounted for as a coinbase transfer
+	profit := b.env.blockProfit()
+	log.Info("buildBlockFromBundles", "num_bundles", len(bundles), "num_txns", len(b.env.txs), "profit", profit.Total())
+	block, sidecars, err := b.buildLegacyBlock(ctx)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	return block, proposerProfit, sidecars, nil
+	return block, profit, sidecars, nil
 }
 
 func envSidecars(env *environment) []*types.BlobTxSidecar {
@@ -0,0 +1,156 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+// mergeCandidate is a bundle considered for merging together with the
+// result of its simulation on top of the builder state.
+type mergeCandidate struct {
+	bundle *suavextypes.Bundle
+	result *suavextypes.SimulateBundleResult
+}
+
+// egp returns the effective gas price paid to the coinbase by the bundle.
+func (c *mergeCandidate) egp() *big.Int {
+	if c.result.Egp == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(c.result.CoinbaseProfit, new(big.Int).SetUint64(c.result.Egp))
+}
+
+// sortCandidates sorts the candidates by decreasing score.
+func sortCandidates(candidates []*mergeCandidate, byProfit bool) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if byProfit {
+			return candidates[i].result.CoinbaseProfit.Cmp(candidates[j].result.CoinbaseProfit) > 0
+		}
+		return candidates[i].egp().Cmp(candidates[j].egp()) > 0
+	})
+}
+
+// MergeBundles greedily merges the bundles into the builder state following
+// the given strategy. Every bundle is first simulated on its own, then the
+// successful ones are applied in order of score. A bundle is dropped if it
+// fails or pays less to the coinbase than in its standalone simulation. Once
+// the deadline of the strategy is reached or the context is done, the bundles
+// merged so far are kept and the remaining ones dropped.
+func (b *Builder) MergeBundles(ctx context.Context, bundles []*suavextypes.Bundle, strategy *suavextypes.MergeStrategy) (*suavextypes.MergeBundlesResult, error) {
+	if strategy == nil {
+		strategy = &suavextypes.MergeStrategy{Name: suavextypes.MergeStrategyGreedyEgp}
+	}
+
+	var byProfit, resimulate bool
+	switch strategy.Name {
+	case suavextypes.MergeStrategyGreedyEgp:
+	case suavextypes.MergeStrategyGreedyProfit:
+		byProfit = true
+	case suavextypes.MergeStrategyGreedyResimulate:
+		resimulate = true
+	default:
+		return nil, fmt.Errorf("%w: unknown merge strategy %q", suavextypes.ErrInvalidParams, strategy.Name)
+	}
+
+	// the deadline interrupts the simulations as well as the merge
+	if strategy.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, time.Now().Add(time.Duration(strategy.Timeout)*time.Millisecond))
+		defer cancel()
+	}
+	expired := func() bool {
+		return ctx.Err() != nil
+	}
+
+	defer b.bindContext(ctx, "MergeBundles")()
+
+	res := &suavextypes.MergeBundlesResult{
+		Landed:  []*suavextypes.SimulateBundleResult{},
+		Dropped: []*suavextypes.DroppedBundle{},
+		Profit:  new(big.Int),
+	}
+	drop := func(c *mergeCandidate, reason string) {
+		res.Dropped = append(res.Dropped, &suavextypes.DroppedBundle{
+			Hash:   c.result.Hash,
+			Reason: reason,
+		})
+	}
+
+	// simulate the candidates on top of the given environment and discard the failing ones
+	simulate := func(env *environment, bundles []*suavextypes.Bundle) []*mergeCandidate {
+		results, _ := b.simulateBundles(bundles, env)
+
+		candidates := make([]*mergeCandidate, 0, len(bundles))
+		for i, result := range results {
+			c := &mergeCandidate{bundle: bundles[i], result: result}
+			if !result.Success {
+				if expired() {
+					drop(c, "deadline exceeded")
+				} else {
+					drop(c, fmt.Sprintf("simulation failed: %s", result.Error))
+				}
+				continue
+			}
+			candidates = append(candidates, c)
+		}
+		sortCandidates(candidates, byProfit)
+		return candidates
+	}
+
+	candidates := simulate(b.env, bundles)
+
+	for len(candidates) > 0 {
+		if expired() {
+			for _, c := range candidates {
+				drop(c, "deadline exceeded")
+			}
+			break
+		}
+
+		c := candidates[0]
+		candidates = candidates[1:]
+
+		cp := b.checkpoint(b.env)
+		balancePre := b.env.state.GetBalance(b.env.coinbase)
+		result, tracer, err := b.addBundle(c.bundle, b.env)
+
+		var reason string
+		if err != nil && expired() {
+			reason = "deadline exceeded"
+		} else if err != nil {
+			reason = fmt.Sprintf("failed after merge: %s", result.Error)
+		} else {
+			result.CoinbaseProfit = new(big.Int).Sub(b.env.state.GetBalance(b.env.coinbase).ToBig(), balancePre.ToBig())
+			if result.CoinbaseProfit.Cmp(c.result.CoinbaseProfit) < 0 {
+				reason = fmt.Sprintf("profit decreased after merge from %s to %s", c.result.CoinbaseProfit, result.CoinbaseProfit)
+			}
+		}
+		if reason != "" {
+			cp.revert()
+			drop(c, reason)
+
+			// the bundle conflicts with the ones already merged, the scores
+			// of the remaining ones might be stale as well
+			if resimulate && len(candidates) > 0 && !expired() {
+				remaining := make([]*suavextypes.Bundle, len(candidates))
+				for i, c := range candidates {
+					remaining[i] = c.bundle
+				}
+				candidates = simulate(b.env, remaining)
+			}
+			continue
+		}
+
+		cp.discard()
+		b.bundleAccess[result.Hash] = tracer
+		res.Landed = append(res.Landed, result)
+		res.Profit.Add(res.Profit, result.CoinbaseProfit)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,57 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+var (
+	// simulationMeterName is the prefix of the simulation meters, followed by
+	// the kind of the simulated item and its outcome
+	simulationMeterName = "suave/builder/simulation"
+
+	bidMeter          = metrics.NewRegisteredMeter("suave/builder/bid", nil)
+	bidValueHistogram = metrics.NewRegisteredHistogram("suave/builder/bid/value", nil, metrics.NewExpDecaySample(1028, 0.015)) // in gwei
+	buildBlockTimer   = metrics.NewRegisteredTimer("suave/builder/buildblock", nil)
+)
+
+// errorClasses are the names of the failed simulations in the metrics, by
+// error code. The other failures are counted as "execution".
+var errorClasses = map[int]string{
+	suavextypes.CodeInvalidParams:     "invalidparams",
+	suavextypes.CodeInvalidBundle:     "invalidbundle",
+	suavextypes.CodeBuildInterrupted:  "interrupted",
+	suavextypes.CodeConstraintNotMet:  "constraint",
+	suavextypes.CodeDeniedAddress:     "denied",
+	suavextypes.CodeNonceTooLow:       "noncetoolow",
+	suavextypes.CodeNonceTooHigh:      "noncetoohigh",
+	suavextypes.CodeInsufficientFunds: "insufficientfunds",
+	suavextypes.CodeGasLimitReached:   "gaslimit",
+	suavextypes.CodeIntrinsicGas:      "intrinsicgas",
+	suavextypes.CodeFeeCapTooLow:      "feecaptoolow",
+}
+
+// markSimulation counts the simulation of a transaction or a bundle by outcome.
+func markSimulation(kind string, err error) {
+	outcome := "success"
+	if err != nil {
+		class, ok := errorClasses[suavextypes.ErrorCode(err)]
+		if !ok {
+			class = "execution"
+		}
+		outcome = "failure/" + class
+	}
+	metrics.GetOrRegisterMeter(simulationMeterName+"/"+kind+"/"+outcome, nil).Mark(1)
+}
+
+// markBid counts a bid of the given value in wei.
+func markBid(value *big.Int) {
+	bidMeter.Mark(1)
+	gwei := new(big.Int).Div(value, big.NewInt(params.GWei))
+	if gwei.IsInt64() {
+		bidValueHistogram.Update(gwei.Int64())
+	}
+}
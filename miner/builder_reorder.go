@@ -0,0 +1,139 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+var (
+	ErrInvalidItemIndex = fmt.Errorf("%w: invalid item index", suavextypes.ErrInvalidParams)
+	ErrEmptyItem        = fmt.Errorf("%w: empty item", suavextypes.ErrInvalidParams)
+)
+
+// bundleSpan is a bundle applied to the environment and the range of the
+// environment transactions it added.
+type bundleSpan struct {
+	start, end int
+	bundle     *suavextypes.Bundle
+}
+
+// sessionItem is a transaction or a bundle applied to the environment.
+type sessionItem struct {
+	tx     *types.Transaction
+	bundle *suavextypes.Bundle
+}
+
+// items returns the transactions and bundles applied to the environment, in
+// order. The transactions that are not part of a bundle are items on their own,
+// with their blob sidecar if any.
+func (env *environment) items() []sessionItem {
+	var (
+		items   []sessionItem
+		blobs   int
+		nextTxs = func(i, end int, item bool) {
+			for _, tx := range env.txs[i:end] {
+				if tx.Type() == types.BlobTxType {
+					tx = tx.WithBlobTxSidecar(env.sidecars[blobs])
+					blobs++
+				}
+				if item {
+					items = append(items, sessionItem{tx: tx})
+				}
+			}
+		}
+		i int
+	)
+	for _, span := range env.bundles {
+		nextTxs(i, span.start, true)
+		nextTxs(span.start, span.end, false)
+		items = append(items, sessionItem{bundle: span.bundle})
+		i = span.end
+	}
+	nextTxs(i, len(env.txs), true)
+	return items
+}
+
+// InsertAt inserts the transactions or the bundle at the given position of the
+// items of the builder, and rebuilds the environment from the parent block by
+// applying the items again in order. The items failing after the change are
+// dropped and reported in the results.
+func (b *Builder) InsertAt(ctx context.Context, index int, item suavextypes.TxsOrBundle) ([]*suavextypes.SessionItemResult, error) {
+	ctx, span := telemetry.Start(ctx, "miner.Builder.InsertAt")
+	defer span.End()
+
+	var inserted []sessionItem
+	if item.Bundle != nil {
+		inserted = append(inserted, sessionItem{bundle: item.Bundle})
+	}
+	for _, tx := range item.Txs {
+		inserted = append(inserted, sessionItem{tx: tx})
+	}
+	if len(inserted) == 0 {
+		return nil, ErrEmptyItem
+	}
+
+	items := b.env.items()
+	if index < 0 || index > len(items) {
+		return nil, fmt.Errorf("%w: %d, builder has %d items", ErrInvalidItemIndex, index, len(items))
+	}
+	items = append(items[:index], append(inserted, items[index:]...)...)
+	return b.rebuild(ctx, items)
+}
+
+// Remove removes the item at the given position and rebuilds the environment
+// like InsertAt.
+func (b *Builder) Remove(ctx context.Context, index int) ([]*suavextypes.SessionItemResult, error) {
+	ctx, span := telemetry.Start(ctx, "miner.Builder.Remove")
+	defer span.End()
+
+	items := b.env.items()
+	if index < 0 || index >= len(items) {
+		return nil, fmt.Errorf("%w: %d, builder has %d items", ErrInvalidItemIndex, index, len(items))
+	}
+	items = append(items[:index], items[index+1:]...)
+	return b.rebuild(ctx, items)
+}
+
+// rebuild applies the items on a copy of the environment the builder started
+// from. The builder is left unchanged if the context is done.
+func (b *Builder) rebuild(ctx context.Context, items []sessionItem) ([]*suavextypes.SessionItemResult, error) {
+	if b.base == nil {
+		return nil, fmt.Errorf("rebuild %w", suavextypes.ErrUnavailable.WithData(&suavextypes.ResourceErrorData{Resource: "rebuild"}))
+	}
+	env := b.base.copy()
+	env.ctx = ctx
+
+	results := make([]*suavextypes.SessionItemResult, 0, len(items))
+	for _, item := range items {
+		if item.bundle != nil {
+			cp := b.checkpoint(env)
+			res, tracer, err := b.addBundle(item.bundle, env)
+			if errors.Is(err, ErrBuildInterrupted) {
+				return nil, err
+			}
+			results = append(results, &suavextypes.SessionItemResult{Hash: res.Hash, Bundle: res})
+			if err != nil {
+				cp.revert()
+				continue
+			}
+			cp.discard()
+			b.bundleAccess[res.Hash] = tracer
+			continue
+		}
+
+		res, _, err := b.addTransaction(item.tx, env)
+		if errors.Is(err, ErrBuildInterrupted) {
+			return nil, err
+		}
+		results = append(results, &suavextypes.SessionItemResult{Hash: item.tx.Hash(), Tx: res})
+	}
+
+	env.ctx = nil
+	b.env = env
+	return results, nil
+}
@@ -1,26 +1,38 @@
 package miner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/params"
 	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestBuilder_AddTxn_Simple(t *testing.T) {
@@ -32,7 +44,7 @@ func TestBuilder_AddTxn_Simple(t *testing.T) {
 
 	tx1 := backend.newRandomTx(false)
 
-	res, err := builder.AddTransaction(tx1)
+	res, err := builder.AddTransaction(context.Background(), tx1)
 	require.NoError(t, err)
 	require.True(t, res.Success)
 	require.Len(t, builder.env.receipts, 1)
@@ -40,7 +52,7 @@ func TestBuilder_AddTxn_Simple(t *testing.T) {
 
 	// we cannot add the same transaction again. Note that by design the
 	// function does not error but returns the SimulateTransactionResult.success = false
-	res, err = builder.AddTransaction(tx1)
+	res, err = builder.AddTransaction(context.Background(), tx1)
 	require.NoError(t, err)
 	require.False(t, res.Success)
 	require.Len(t, builder.env.receipts, 1)
@@ -56,7 +68,7 @@ func TestBuilder_AddTxns_Simple(t *testing.T) {
 	tx1 := backend.newRandomTx(false)
 	tx2 := backend.newRandomTxWithNonce(1)
 
-	res, err := builder.AddTransactions([]*types.Transaction{tx1, tx2})
+	res, err := builder.AddTransactions(context.Background(), []*types.Transaction{tx1, tx2})
 	require.NoError(t, err)
 	require.Len(t, res, 2)
 	for _, r := range res {
@@ -67,7 +79,7 @@ func TestBuilder_AddTxns_Simple(t *testing.T) {
 	tx3 := backend.newRandomTxWithNonce(2)
 	tx4 := backend.newRandomTxWithNonce(1000) // fails with nonce too high
 
-	res, err = builder.AddTransactions([]*types.Transaction{tx3, tx4})
+	res, err = builder.AddTransactions(context.Background(), []*types.Transaction{tx3, tx4})
 	require.NoError(t, err)
 	require.Len(t, res, 2)
 	require.True(t, res[0].Success)
@@ -96,7 +108,7 @@ func TestBuilder_AddBundles_Simple(t *testing.T) {
 		Txs: []*types.Transaction{tx3, tx4},
 	}
 
-	res, err := builder.AddBundles([]*suavextypes.Bundle{bundle1, bundle2})
+	res, err := builder.AddBundles(context.Background(), []*suavextypes.Bundle{bundle1, bundle2})
 	require.NoError(t, err)
 	require.Len(t, res, 2)
 	require.True(t, res[0].Success)
@@ -117,7 +129,7 @@ func TestBuilder_AddBundles_RevertHashes(t *testing.T) {
 		Txs: []*types.Transaction{tx1, tx2},
 	}
 
-	res, err := builder.AddBundles([]*suavextypes.Bundle{bundle})
+	res, err := builder.AddBundles(context.Background(), []*suavextypes.Bundle{bundle})
 	require.NoError(t, err)
 	require.Len(t, res, 1)
 	require.False(t, res[0].Success)
@@ -128,7 +140,7 @@ func TestBuilder_AddBundles_RevertHashes(t *testing.T) {
 
 	bundle.RevertingHashes = []common.Hash{tx2.Hash()}
 
-	res, err = builder.AddBundles([]*suavextypes.Bundle{bundle})
+	res, err = builder.AddBundles(context.Background(), []*suavextypes.Bundle{bundle})
 	require.NoError(t, err)
 	require.Len(t, res, 1)
 	require.True(t, res[0].Success)
@@ -156,7 +168,7 @@ func TestBuilder_AddBundles_InvalidParams(t *testing.T) {
 		BlockNumber: big.NewInt(20),
 	}
 
-	res, err := builder.AddBundles([]*suavextypes.Bundle{bundle})
+	res, err := builder.AddBundles(context.Background(), []*suavextypes.Bundle{bundle})
 	require.NoError(t, err)
 	require.Len(t, res, 1)
 	require.False(t, res[0].Success)
@@ -170,7 +182,7 @@ func TestBuilder_AddBundles_InvalidParams(t *testing.T) {
 		MaxBlock:    big.NewInt(6),
 	}
 
-	res, err = builder.AddBundles([]*suavextypes.Bundle{bundle})
+	res, err = builder.AddBundles(context.Background(), []*suavextypes.Bundle{bundle})
 	require.NoError(t, err)
 	require.Len(t, res, 1)
 	require.False(t, res[0].Success)
@@ -182,7 +194,7 @@ func TestBuilder_AddBundles_InvalidParams(t *testing.T) {
 		Txs: []*types.Transaction{},
 	}
 
-	res, err = builder.AddBundles([]*suavextypes.Bundle{bundle})
+	res, err = builder.AddBundles(context.Background(), []*suavextypes.Bundle{bundle})
 	require.NoError(t, err)
 	require.False(t, res[0].Success)
 	require.Equal(t, ErrEmptyTxs.Error(), res[0].Error)
@@ -190,6 +202,421 @@ func TestBuilder_AddBundles_InvalidParams(t *testing.T) {
 	require.True(t, builder.env.state.GetBalance(testUserAddress).IsZero())
 }
 
+func TestBuilder_AddBundles_Constraints(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx0 := backend.newRandomTxWithNonce(0)
+	_, err = builder.AddTransaction(ctx, tx0)
+	require.NoError(t, err)
+
+	next := []*types.Transaction{backend.newRandomTxWithNonce(1)}
+	simulate := func(c *types.BundleConstraints) *suavextypes.SimulateBundleResult {
+		res, err := builder.SimulateBundle(ctx, &suavextypes.Bundle{Txs: next, Constraints: c})
+		require.NoError(t, err)
+		return res
+	}
+	time := builder.env.header.Time
+	after := tx0.Hash()
+
+	cases := []struct {
+		name        string
+		constraints *types.BundleConstraints
+		failed      string
+	}{
+		{"Timestamp", &types.BundleConstraints{MinTimestamp: time, MaxTimestamp: time}, ""},
+		{"TooEarly", &types.BundleConstraints{MinTimestamp: time + 1}, constraintTimestamp},
+		{"TooLate", &types.BundleConstraints{MaxTimestamp: time - 1}, constraintTimestamp},
+		{"TopOfBlock", &types.BundleConstraints{TopOfBlock: true}, constraintTopOfBlock},
+		{"After", &types.BundleConstraints{After: &after}, ""},
+		{"AfterOther", &types.BundleConstraints{After: &common.Hash{0x1}}, constraintAfter},
+		{"Balance", &types.BundleConstraints{State: []*types.StatePredicate{
+			{Address: testUserAddress, Op: types.PredicateEq, Value: (*hexutil.Big)(big.NewInt(1000))},
+		}}, ""},
+		{"BalanceTooLow", &types.BundleConstraints{State: []*types.StatePredicate{
+			{Address: testUserAddress, Op: types.PredicateGte, Value: (*hexutil.Big)(big.NewInt(0))},
+			{Address: testUserAddress, Op: types.PredicateGt, Value: (*hexutil.Big)(big.NewInt(1000))},
+		}}, "state[1]"},
+		{"Storage", &types.BundleConstraints{State: []*types.StatePredicate{
+			{Address: testUserAddress, Slot: &common.Hash{}, Op: types.PredicateLt, Value: (*hexutil.Big)(big.NewInt(1))},
+		}}, ""},
+		{"CoinbasePayment", &types.BundleConstraints{MinCoinbasePaymentPerGas: (*hexutil.Big)(big.NewInt(1))}, ""},
+		{"CoinbasePaymentTooLow", &types.BundleConstraints{MinCoinbasePaymentPerGas: (*hexutil.Big)(big.NewInt(params.Ether))}, constraintCoinbasePayment},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := simulate(c.constraints)
+			require.Equal(t, c.failed, res.FailedConstraint)
+			require.Equal(t, c.failed == "", res.Success, res.Error)
+			if c.failed != "" {
+				require.Contains(t, res.Error, ErrConstraintNotMet.Error())
+			}
+		})
+	}
+
+	// the constraints that can never be met are rejected
+	res := simulate(&types.BundleConstraints{MinTimestamp: 2, MaxTimestamp: 1})
+	require.Contains(t, res.Error, ErrInvalidConstraints.Error())
+	res = simulate(&types.BundleConstraints{State: []*types.StatePredicate{{Op: "in", Value: new(hexutil.Big)}}})
+	require.Contains(t, res.Error, errUnknownPredicateOp.Error())
+}
+
+func TestBuilder_SimulateBundles(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	// both bundles spend the same nonce, they can only be simulated independently
+	bundle1 := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(0)},
+	}
+	bundle2 := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(0), backend.newRandomTxWithNonce(1)},
+	}
+	bundle3 := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(1000)}, // fails with nonce too high
+	}
+
+	res, err := builder.SimulateBundles(context.Background(), []*suavextypes.Bundle{bundle1, bundle2, bundle3})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	require.True(t, res[0].Success)
+	require.Equal(t, params.TxGas, res[0].Egp)
+	require.Equal(t, 1, res[0].CoinbaseProfit.Sign())
+	require.Equal(t, bundle1.Hash(), res[0].Hash)
+	require.Contains(t, res[0].Writes.Accounts, testBankAddress)
+	require.Contains(t, res[0].Writes.Accounts, testUserAddress)
+
+	require.True(t, res[1].Success)
+	require.Equal(t, 2*params.TxGas, res[1].Egp)
+	require.Equal(t, new(big.Int).Mul(res[0].CoinbaseProfit, big.NewInt(2)), res[1].CoinbaseProfit)
+
+	require.False(t, res[2].Success)
+	require.Zero(t, res[2].CoinbaseProfit.Sign())
+
+	// the builder state is not modified by the simulations
+	require.Len(t, builder.env.txs, 0)
+	require.True(t, builder.env.state.GetBalance(testUserAddress).IsZero())
+}
+
+func TestBuilder_SimulateTransactionAndBundle(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	gas := builder.env.gasPool.Gas()
+
+	res, err := builder.SimulateTransaction(context.Background(), backend.newRandomTxWithNonce(0))
+	require.NoError(t, err)
+	require.True(t, res.Success, res.Error)
+	require.Equal(t, params.TxGas, res.Egp)
+	require.Contains(t, res.Writes.Accounts, testUserAddress)
+
+	// the same nonce can be simulated again since nothing was committed
+	bundle := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(0), backend.newRandomTxWithNonce(1)},
+	}
+	bundleRes, err := builder.SimulateBundle(context.Background(), bundle)
+	require.NoError(t, err)
+	require.True(t, bundleRes.Success, bundleRes.Error)
+	require.Equal(t, 2*params.TxGas, bundleRes.Egp)
+	require.Equal(t, 1, bundleRes.CoinbaseProfit.Sign())
+
+	require.Len(t, builder.env.txs, 0)
+	require.Zero(t, builder.env.tcount)
+	require.Equal(t, gas, builder.env.gasPool.Gas())
+	require.Zero(t, builder.env.header.GasUsed)
+	require.True(t, builder.env.state.GetBalance(testUserAddress).IsZero())
+
+	// and the simulation is the one of the committed transaction
+	added, err := builder.AddTransaction(context.Background(), backend.newRandomTxWithNonce(0))
+	require.NoError(t, err)
+	require.Equal(t, res, added)
+}
+
+func TestBuilder_Usage(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+	start := builder.Usage()
+
+	_, err = builder.SimulateTransaction(context.Background(), backend.newRandomTxWithNonce(0))
+	require.NoError(t, err)
+	bundle := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(0), backend.newRandomTxWithNonce(5)},
+	}
+	_, err = builder.SimulateBundles(context.Background(), []*suavextypes.Bundle{bundle, bundle})
+	require.NoError(t, err)
+
+	// the simulations are spent by the builder, the failed transactions use
+	// no gas and only the concurrent simulations copy the state
+	usage := builder.Usage()
+	require.Equal(t, 3*params.TxGas, usage.Gas-start.Gas)
+	require.Equal(t, uint64(5), usage.Txs-start.Txs)
+	require.Equal(t, uint64(2), usage.Bundles-start.Bundles)
+	require.Equal(t, uint64(2), usage.StateCopies-start.StateCopies)
+	require.Positive(t, usage.CPUTime)
+}
+
+func TestBuilder_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := telemetry.NewWithProcessor(telemetry.Config{}, recorder)
+	telemetry.SetTracer(tracer)
+	defer telemetry.SetTracer(nil)
+
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	// the spans of the calls are the children of the one of their caller
+	ctx, parent := telemetry.Start(context.Background(), "request")
+
+	tx := backend.newRandomTxWithNonce(0)
+	_, err = builder.SimulateTransaction(ctx, tx)
+	require.NoError(t, err)
+	_, err = builder.AddTransaction(ctx, tx)
+	require.NoError(t, err)
+	// the bundles are simulated on copies of the state
+	bundleTx := backend.newRandomTxWithNonce(1)
+	bundle := &suavextypes.Bundle{Txs: types.Transactions{bundleTx}}
+	_, err = builder.SimulateBundles(ctx, []*suavextypes.Bundle{bundle})
+	require.NoError(t, err)
+	_, err = builder.BuildBlock(ctx)
+	require.NoError(t, err)
+	parent.End()
+
+	var (
+		names []string
+		calls = make(map[trace.SpanID]string)
+	)
+	for _, span := range recorder.Ended() {
+		require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		names = append(names, span.Name())
+		calls[span.SpanContext().SpanID()] = span.Name()
+	}
+	require.Equal(t, []string{
+		"miner.applyTransaction", "miner.Builder.SimulateTransaction",
+		"miner.applyTransaction", "miner.Builder.AddTransaction",
+		"miner.copyState", "miner.applyTransaction", "miner.Builder.SimulateBundles",
+		"miner.finalizeAndAssemble", "miner.Builder.BuildBlock",
+		"request",
+	}, names)
+
+	// the work on the state is traced in the span of the call
+	var applied []attribute.KeyValue
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "miner.applyTransaction":
+			applied = append(applied, span.Attributes()[0])
+			require.Contains(t, calls[span.Parent().SpanID()], "miner.Builder.")
+		case "miner.copyState":
+			require.Equal(t, "miner.Builder.SimulateBundles", calls[span.Parent().SpanID()])
+		case "miner.finalizeAndAssemble":
+			require.Equal(t, "miner.Builder.BuildBlock", calls[span.Parent().SpanID()])
+		}
+	}
+	require.Equal(t, []attribute.KeyValue{
+		attribute.String("tx.hash", tx.Hash().Hex()),
+		attribute.String("tx.hash", tx.Hash().Hex()),
+		attribute.String("tx.hash", bundleTx.Hash().Hex()),
+	}, applied)
+}
+
+func TestBuilder_InsertAtRemove(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx0 := backend.newRandomTxWithNonce(0)
+	_, err = builder.AddTransaction(ctx, tx0)
+	require.NoError(t, err)
+
+	_, err = builder.InsertAt(ctx, 2, suavextypes.TxsOrBundle{Txs: types.Transactions{tx0}})
+	require.ErrorIs(t, err, ErrInvalidItemIndex)
+	_, err = builder.InsertAt(ctx, 0, suavextypes.TxsOrBundle{})
+	require.ErrorIs(t, err, ErrEmptyItem)
+
+	// the bundle lands behind the transaction it depends on
+	bundle := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(1), backend.newRandomTxWithNonce(2)},
+	}
+	res, err := builder.InsertAt(ctx, 1, suavextypes.TxsOrBundle{Bundle: bundle})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, tx0.Hash(), res[0].Hash)
+	require.True(t, res[0].Tx.Success)
+	require.Equal(t, bundle.Hash(), res[1].Hash)
+	require.True(t, res[1].Bundle.Success, res[1].Bundle.Error)
+	require.Len(t, builder.env.txs, 3)
+	require.Equal(t, 3*params.TxGas, builder.env.header.GasUsed)
+
+	// without the transaction the bundle fails and is dropped
+	res, err = builder.Remove(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.False(t, res[0].Bundle.Success)
+	require.Len(t, builder.env.txs, 0)
+	require.Zero(t, builder.env.header.GasUsed)
+	require.True(t, builder.env.state.GetBalance(testUserAddress).IsZero())
+
+	_, err = builder.Remove(ctx, 0)
+	require.ErrorIs(t, err, ErrInvalidItemIndex)
+
+	// the transactions are inserted as one item each
+	res, err = builder.InsertAt(ctx, 0, suavextypes.TxsOrBundle{Txs: types.Transactions{tx0, backend.newRandomTxWithNonce(1)}})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	res, err = builder.Remove(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, builder.env.txs, 1)
+}
+
+func TestBuilder_CheckBundleConflict(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	input, err := suaveExample1Artifact.Abi.Pack("increment")
+	require.NoError(t, err)
+
+	bundle1 := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newRandomTxWithNonce(0)},
+	}
+	bundle2 := &suavextypes.Bundle{
+		Txs: []*types.Transaction{backend.newCall(suaveExample1Addr, input)},
+	}
+
+	res, err := builder.SimulateBundles(context.Background(), []*suavextypes.Bundle{bundle1, bundle2})
+	require.NoError(t, err)
+	require.True(t, res[0].Success)
+	require.True(t, res[1].Success)
+
+	// the transaction results report the state accessed by the contract call
+	txRes := res[1].SimulateTransactionResults[0]
+	require.Contains(t, txRes.Reads.Accounts, suaveExample1Addr)
+	require.Len(t, txRes.Writes.Storage[suaveExample1Addr], 1)
+	require.Len(t, res[1].Writes.Storage[suaveExample1Addr], 1)
+
+	// both bundles are sent by the same account
+	conflict, err := builder.CheckBundleConflict(bundle1.Hash(), bundle2.Hash())
+	require.NoError(t, err)
+	require.True(t, conflict.Conflict)
+	require.Contains(t, conflict.State.Accounts, testBankAddress)
+	require.NotContains(t, conflict.State.Accounts, suaveExample1Addr)
+
+	_, err = builder.CheckBundleConflict(bundle1.Hash(), common.Hash{})
+	require.Error(t, err)
+}
+
+func TestBuilder_MergeBundles(t *testing.T) {
+	t.Parallel()
+
+	newTx := func(nonce uint64, gasPrice int64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, testUserAddress, big.NewInt(1000), params.TxGas, big.NewInt(gasPrice*params.InitialBaseFee), nil), types.HomesteadSigner{}, testBankKey)
+		require.NoError(t, err)
+		return tx
+	}
+
+	// low and high pay for the same nonce, only one of them can land
+	low := &suavextypes.Bundle{Txs: []*types.Transaction{newTx(0, 10)}}
+	high := &suavextypes.Bundle{Txs: []*types.Transaction{newTx(0, 20)}}
+	invalid := &suavextypes.Bundle{Txs: []*types.Transaction{newTx(1000, 10)}}
+
+	strategies := []string{
+		suavextypes.MergeStrategyGreedyEgp,
+		suavextypes.MergeStrategyGreedyProfit,
+		suavextypes.MergeStrategyGreedyResimulate,
+	}
+	for _, strategy := range strategies {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			config, _ := newMockBuilderConfig(t)
+			builder, err := NewBuilder(config, &BuilderArgs{})
+			require.NoError(t, err)
+
+			res, err := builder.MergeBundles(context.Background(), []*suavextypes.Bundle{low, high, invalid}, &suavextypes.MergeStrategy{Name: strategy})
+			require.NoError(t, err)
+
+			require.Len(t, res.Landed, 1)
+			require.Equal(t, high.Hash(), res.Landed[0].Hash)
+			require.Equal(t, res.Landed[0].CoinbaseProfit, res.Profit)
+
+			require.Len(t, res.Dropped, 2)
+			require.Equal(t, invalid.Hash(), res.Dropped[0].Hash)
+			require.Contains(t, res.Dropped[0].Reason, "simulation failed")
+			require.Equal(t, low.Hash(), res.Dropped[1].Hash)
+			require.Contains(t, res.Dropped[1].Reason, "failed after merge")
+
+			// the landed bundles are committed to the builder state
+			require.Len(t, builder.env.txs, 1)
+			require.Equal(t, big.NewInt(1000), builder.env.state.GetBalance(testUserAddress).ToBig())
+		})
+	}
+
+	config, _ := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	_, err = builder.MergeBundles(context.Background(), []*suavextypes.Bundle{low}, &suavextypes.MergeStrategy{Name: "unknown"})
+	require.ErrorIs(t, err, suavextypes.ErrInvalidParams)
+}
+
+func TestBuilder_MergeBundlesDeadline(t *testing.T) {
+	t.Parallel()
+	config, _ := newMockBuilderConfig(t)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	// the creation code loops until it runs out of gas: JUMPDEST PUSH1 0 JUMP
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 4_000_000, gasPrice, common.FromHex("0x5b600056")), types.HomesteadSigner{}, testBankKey)
+	bundle := &suavextypes.Bundle{Txs: types.Transactions{tx}}
+
+	// the deadline interrupts the standalone simulation of the bundle
+	res, err := builder.MergeBundles(context.Background(), []*suavextypes.Bundle{bundle}, &suavextypes.MergeStrategy{Name: suavextypes.MergeStrategyGreedyEgp, Timeout: 1})
+	require.NoError(t, err)
+	require.Empty(t, res.Landed)
+	require.Len(t, res.Dropped, 1)
+	require.Equal(t, "deadline exceeded", res.Dropped[0].Reason)
+	require.Empty(t, builder.Transactions())
+}
+
+func TestAccessTracer_Conflicts(t *testing.T) {
+	var (
+		addr = common.Address{0x1}
+		slot = common.Hash{0x2}
+	)
+
+	reader := newAccessTracer()
+	reader.reads.addSlot(addr, slot)
+
+	writer := newAccessTracer()
+	writer.writes.addSlot(addr, slot)
+
+	// two readers never conflict
+	require.True(t, reader.conflicts(reader).empty())
+
+	// a write conflicts with a read of the same slot in any order
+	require.False(t, reader.conflicts(writer).empty())
+	require.False(t, writer.conflicts(reader).empty())
+
+	// writes to other slots do not conflict
+	other := newAccessTracer()
+	other.writes.addSlot(addr, common.Hash{0x3})
+	require.True(t, reader.conflicts(other).empty())
+}
+
 func TestBuilder_FillTransactions(t *testing.T) {
 	t.Parallel()
 	config, backend := newMockBuilderConfig(t)
@@ -205,13 +632,98 @@ func TestBuilder_FillTransactions(t *testing.T) {
 	errArr = backend.TxPool().Add(types.Transactions{tx2}, false, true)
 	require.NoError(t, errArr[0])
 
-	require.NoError(t, builder.FillPending())
+	res, err := builder.FillPending(context.Background(), nil)
+	require.NoError(t, err)
 	require.Len(t, builder.env.receipts, 2)
+	require.Equal(t, []common.Hash{tx1.Hash(), tx2.Hash()}, res.Txs)
 
 	require.Equal(t, tx1.Hash(), builder.env.receipts[0].TxHash)
 	require.Equal(t, tx2.Hash(), builder.env.receipts[1].TxHash)
 }
 
+func TestBuilder_FillPendingOpts(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	txs := types.Transactions{backend.newRandomTxWithNonce(0), backend.newRandomTxWithNonce(1), backend.newRandomTxWithNonce(2)}
+	for _, err := range backend.TxPool().Add(txs, false, true) {
+		require.NoError(t, err)
+	}
+
+	// the excluded senders and recipients are skipped
+	res, err := builder.FillPending(context.Background(), &suavextypes.FillPendingOpts{ExcludedSenders: []common.Address{testBankAddress}})
+	require.NoError(t, err)
+	require.Empty(t, res.Txs)
+
+	res, err = builder.FillPending(context.Background(), &suavextypes.FillPendingOpts{ExcludedRecipients: []common.Address{testUserAddress}})
+	require.NoError(t, err)
+	require.Empty(t, res.Txs)
+
+	// the number of transactions is capped
+	res, err = builder.FillPending(context.Background(), &suavextypes.FillPendingOpts{MaxTxs: 1})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{txs[0].Hash()}, res.Txs)
+	require.Equal(t, params.TxGas, res.GasUsed)
+
+	// the reserved gas is left available after filling
+	available := builder.env.gasPool.Gas()
+	_, err = builder.FillPending(context.Background(), &suavextypes.FillPendingOpts{ReservedGas: available + 1})
+	require.ErrorIs(t, err, suavextypes.ErrInvalidParams)
+
+	res, err = builder.FillPending(context.Background(), &suavextypes.FillPendingOpts{ReservedGas: available - params.TxGas})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{txs[1].Hash()}, res.Txs)
+	require.Equal(t, available-params.TxGas, builder.env.gasPool.Gas())
+
+	// the min tip must fit in 256 bits
+	for _, tip := range []*big.Int{big.NewInt(-1), new(big.Int).Lsh(common.Big1, 256)} {
+		_, err = builder.FillPending(context.Background(), &suavextypes.FillPendingOpts{MinTip: tip})
+		require.ErrorIs(t, err, suavextypes.ErrInvalidParams)
+	}
+}
+
+func TestBuilder_DenyList(t *testing.T) {
+	t.Parallel()
+	config, _ := newMockBuilderConfig(t)
+
+	denied := common.Address{0xde, 0xad}
+	config.DenyList, _ = NewDenyList("")
+	config.DenyList.Set([]common.Address{denied})
+
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+
+	// the recipient is denied
+	tx, _ := types.SignTx(types.NewTransaction(0, denied, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+	res, err := builder.AddTransaction(context.Background(), tx)
+	require.NoError(t, err)
+	require.False(t, res.Success)
+	require.Contains(t, res.Error, "recipient")
+
+	// the denied address is accessed by the init code: PUSH20 <denied> BALANCE STOP
+	code := append(append([]byte{byte(vm.PUSH20)}, denied.Bytes()...), byte(vm.BALANCE), byte(vm.STOP))
+	tx, _ = types.SignTx(types.NewContractCreation(0, big.NewInt(0), 100000, gasPrice, code), types.HomesteadSigner{}, testBankKey)
+	res, err = builder.AddTransaction(context.Background(), tx)
+	require.NoError(t, err)
+	require.False(t, res.Success)
+	require.Contains(t, res.Error, "accessed during execution")
+
+	// the rejected transactions are not part of the block
+	require.Empty(t, builder.env.txs)
+	require.Zero(t, builder.env.header.GasUsed)
+
+	// once the address is allowed again the transaction lands
+	config.DenyList.Set(nil)
+	res, err = builder.AddTransaction(context.Background(), tx)
+	require.NoError(t, err)
+	require.True(t, res.Success)
+}
+
 func TestBuilder_BuildBlock(t *testing.T) {
 	t.Parallel()
 
@@ -222,15 +734,186 @@ func TestBuilder_BuildBlock(t *testing.T) {
 
 	tx1 := backend.newRandomTx(true)
 
-	_, err = builder.AddTransaction(tx1)
+	_, err = builder.AddTransaction(context.Background(), tx1)
 	require.NoError(t, err)
 
-	block, err := builder.BuildBlock()
+	block, err := builder.BuildBlock(context.Background())
 	require.NoError(t, err)
 	require.NotNil(t, block)
 	require.Len(t, block.Transactions(), 1)
 }
 
+func TestBuilder_NewChild(t *testing.T) {
+	t.Parallel()
+
+	// clique cannot prepare the headers on top of unsealed blocks
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		config = *params.MergedTestChainConfig
+	)
+	config.ShanghaiTime, config.CancunTime = nil, nil
+	w, backend := newTestWorker(t, &config, beacon.New(ethash.NewFaker()), db, 0)
+	bConfig := &BuilderConfig{
+		ChainConfig: w.chainConfig,
+		Engine:      w.engine,
+		EthBackend:  backend,
+		Chain:       w.chain,
+		GasCeil:     10000000,
+	}
+
+	parent, err := NewBuilder(bConfig, &BuilderArgs{})
+	require.NoError(t, err)
+
+	_, err = parent.NewChild(&BuilderArgs{})
+	require.ErrorIs(t, err, ErrBlockNotBuilt)
+
+	_, err = parent.AddTransaction(context.Background(), backend.newRandomTxWithNonce(0))
+	require.NoError(t, err)
+	block, err := parent.BuildBlock(context.Background())
+	require.NoError(t, err)
+
+	child, err := parent.NewChild(&BuilderArgs{})
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), child.ParentHash())
+	require.Equal(t, block.NumberU64()+1, child.BlockNumber().Uint64())
+	require.Equal(t, eip1559.CalcBaseFee(&config, block.Header()), child.env.header.BaseFee)
+
+	// the child continues from the post-state of the parent block
+	res, err := child.AddTransaction(context.Background(), backend.newRandomTxWithNonce(1))
+	require.NoError(t, err)
+	require.True(t, res.Success, res.Error)
+
+	// and the parent is not affected by the child
+	require.Equal(t, uint64(1), parent.env.state.GetNonce(testBankAddress))
+
+	childBlock, err := child.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), childBlock.ParentHash())
+
+	grandchild, err := child.NewChild(&BuilderArgs{})
+	require.NoError(t, err)
+	require.Equal(t, childBlock.Hash(), grandchild.ParentHash())
+	require.Equal(t, uint64(2), grandchild.env.state.GetNonce(testBankAddress))
+}
+
+// newLegacyTestMiner returns a miner with the same configuration as the
+// builders created from the given config.
+func newLegacyTestMiner(config *BuilderConfig) *Miner {
+	return &Miner{
+		config:      &Config{GasCeil: config.GasCeil},
+		chainConfig: config.ChainConfig,
+		engine:      config.Engine,
+		chain:       config.Chain,
+		txpool:      config.EthBackend.TxPool(),
+	}
+}
+
+func TestBuilder_LegacyBuildBlockFromTxs(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	wrk := newLegacyTestMiner(config)
+
+	tx1 := backend.newRandomTx(false)
+	tx2 := backend.newRandomTxWithNonce(1)
+	args := &types.BuildBlockArgs{
+		FeeRecipient: common.Address{0x1},
+		Timestamp:    config.Chain.CurrentBlock().Time + 1,
+	}
+
+	// the legacy block matches the one of a session with the same inputs
+	block, _, _, err := wrk.BuildBlockFromTxs(context.Background(), args, types.Transactions{tx1, tx2})
+	require.NoError(t, err)
+
+	builder, err := NewBuilder(config, &BuilderArgs{FeeRecipient: args.FeeRecipient, Timestamp: args.Timestamp})
+	require.NoError(t, err)
+	_, err = builder.AddTransactions(context.Background(), types.Transactions{tx1, tx2})
+	require.NoError(t, err)
+	expected, err := builder.BuildBlock(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, expected.Root(), block.Root())
+	require.Equal(t, expected.TxHash(), block.TxHash())
+	require.Equal(t, expected.ReceiptHash(), block.ReceiptHash())
+	require.Equal(t, expected.GasUsed(), block.GasUsed())
+
+	// a failing transaction fails the whole block
+	_, _, _, err = wrk.BuildBlockFromTxs(context.Background(), args, types.Transactions{tx2})
+	require.ErrorContains(t, err, "nonce too high")
+}
+
+func TestBuilder_LegacyBuildBlockProfit(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	wrk := newLegacyTestMiner(config)
+
+	timestamp := config.Chain.CurrentBlock().Time + 1
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	tip := func(block *types.Block) *big.Int {
+		return new(big.Int).Mul(new(big.Int).Sub(gasPrice, block.BaseFee()), new(big.Int).SetUint64(block.GasUsed()))
+	}
+
+	// the fee recipient sends the transaction, its balance decreases but the
+	// block is still worth the priority fees
+	tx := backend.newRandomTxWithNonce(0)
+	block, profit, _, err := wrk.BuildBlockFromTxs(context.Background(), &types.BuildBlockArgs{
+		FeeRecipient: testBankAddress,
+		Timestamp:    timestamp,
+	}, types.Transactions{tx})
+	require.NoError(t, err)
+	require.Equal(t, tip(block), profit.PriorityFees.ToInt())
+	require.Zero(t, profit.CoinbaseTransfers.ToInt().Sign())
+	require.Equal(t, tip(block), profit.Total())
+
+	// a transfer to the fee recipient is accounted for on its own
+	feeRecipient := common.Address{0xfe}
+	tx, _ = types.SignTx(types.NewTransaction(0, feeRecipient, big.NewInt(1000), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+	block, profit, _, err = wrk.BuildBlockFromTxs(context.Background(), &types.BuildBlockArgs{
+		FeeRecipient: feeRecipient,
+		Timestamp:    timestamp,
+	}, types.Transactions{tx})
+	require.NoError(t, err)
+	require.Equal(t, tip(block), profit.PriorityFees.ToInt())
+	require.Equal(t, big.NewInt(1000), profit.CoinbaseTransfers.ToInt())
+}
+
+func TestBuilder_LegacyBuildBlockFromBundles(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	wrk := newLegacyTestMiner(config)
+
+	tx1 := backend.newRandomTx(false)
+	tx2 := backend.newRandomTxWithNonce(3) // fails with nonce too high
+	args := &types.BuildBlockArgs{
+		FeeRecipient: common.Address{0xfe},
+		Timestamp:    config.Chain.CurrentBlock().Time + 1,
+	}
+
+	// the bundles are validated like in a session
+	bundle := types.SBundle{Txs: types.Transactions{tx1, tx2}}
+	_, _, _, err := wrk.BuildBlockFromBundles(context.Background(), args, []types.SBundle{bundle})
+	require.ErrorContains(t, err, "nonce too high")
+
+	bundle.RevertingHashes = []common.Hash{tx2.Hash()}
+	block, profit, _, err := wrk.BuildBlockFromBundles(context.Background(), args, []types.SBundle{bundle})
+	require.NoError(t, err)
+	require.Len(t, block.Transactions(), 2) // the bundle transaction and the proposer payment
+	require.Equal(t, tx1.Hash(), block.Transactions()[0].Hash())
+	// the fee recipient is paid by the proposer payment only
+	payment := block.Transactions()[1]
+	require.Zero(t, profit.PriorityFees.ToInt().Sign())
+	require.Equal(t, payment.Value(), profit.CoinbaseTransfers.ToInt())
+	require.Equal(t, 1, profit.Total().Sign())
+
+	bundle = types.SBundle{Txs: types.Transactions{tx1}, BlockNumber: big.NewInt(20)}
+	_, _, _, err = wrk.BuildBlockFromBundles(context.Background(), args, []types.SBundle{bundle})
+	require.ErrorContains(t, err, ErrInvalidBlockNumber.Error())
+
+	bundle = types.SBundle{Txs: types.Transactions{tx1}, Constraints: &types.BundleConstraints{MaxTimestamp: args.Timestamp}}
+	_, _, _, err = wrk.BuildBlockFromBundles(context.Background(), args, []types.SBundle{bundle})
+	require.ErrorContains(t, err, ErrConstraintNotMet.Error())
+	require.ErrorContains(t, err, constraintTimestamp)
+}
+
 func TestBuilder_ContractWithLogs(t *testing.T) {
 	// test that we can simulate a txn with a contract that emits events
 	t.Parallel()
@@ -245,7 +928,7 @@ func TestBuilder_ContractWithLogs(t *testing.T) {
 	builder, err := NewBuilder(config, &BuilderArgs{})
 	require.NoError(t, err)
 
-	simResult, err := builder.AddTransaction(tx)
+	simResult, err := builder.AddTransaction(context.Background(), tx)
 	require.NoError(t, err)
 	require.True(t, simResult.Success)
 	require.Len(t, simResult.Logs, 1)
@@ -254,6 +937,95 @@ func TestBuilder_ContractWithLogs(t *testing.T) {
 	require.Equal(t, simResult.Logs[0].Topics[0], suaveExample1Artifact.Abi.Events["SomeEvent"].ID)
 }
 
+func TestBuilder_ContextCancelled(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the transactions are not applied once the context is done
+	tx := backend.newRandomTx(false)
+	_, err = builder.AddTransaction(ctx, tx)
+	require.ErrorIs(t, err, ErrBuildInterrupted)
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = builder.AddBundles(ctx, []*suavextypes.Bundle{{Txs: types.Transactions{tx}}})
+	require.ErrorIs(t, err, ErrBuildInterrupted)
+	require.Empty(t, builder.Transactions())
+
+	// filling and merging return what was done so far
+	backend.txPool.Add([]*types.Transaction{tx}, true, true)
+	res, err := builder.FillPending(ctx, nil)
+	require.NoError(t, err)
+	require.True(t, res.DeadlineExceeded)
+	require.Empty(t, res.Txs)
+
+	merged, err := builder.MergeBundles(ctx, []*suavextypes.Bundle{{Txs: types.Transactions{tx}}}, nil)
+	require.NoError(t, err)
+	require.Empty(t, merged.Landed)
+	require.Len(t, merged.Dropped, 1)
+
+	// the block can still be built from the untouched state
+	_, err = builder.AddTransaction(context.Background(), tx)
+	require.NoError(t, err)
+	require.Len(t, builder.Transactions(), 1)
+}
+
+func TestBuilder_ContextCancelledDuringExecution(t *testing.T) {
+	t.Parallel()
+	config, _ := newMockBuilderConfig(t)
+
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(t, err)
+
+	// the creation code loops forever: JUMPDEST PUSH1 0 JUMP
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 1_000_000, gasPrice, common.FromHex("0x5b600056")), types.HomesteadSigner{}, testBankKey)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// cancel the context once the loop is running and wait for the EVM to
+	// be cancelled
+	env := builder.env
+	env.ctx = ctx
+	env.tracer = &tracing.Hooks{
+		OnOpcode: func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+			if ctx.Err() == nil {
+				cancel()
+				time.Sleep(50 * time.Millisecond)
+			}
+		},
+	}
+	gasPool, gasUsed := env.gasPool.Gas(), env.header.GasUsed
+
+	_, err = builder.wrk.applyTransaction(env, tx)
+	require.ErrorIs(t, err, ErrBuildInterrupted)
+
+	// the partial execution is reverted
+	require.Zero(t, env.state.GetNonce(testBankAddress))
+	require.Equal(t, gasPool, env.gasPool.Gas())
+	require.Equal(t, gasUsed, env.header.GasUsed)
+}
+
+func TestBuilder_LegacyBuildBlockContextCancelled(t *testing.T) {
+	t.Parallel()
+	config, backend := newMockBuilderConfig(t)
+	wrk := newLegacyTestMiner(config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	args := &types.BuildBlockArgs{Timestamp: config.Chain.CurrentBlock().Time + 1}
+	_, _, _, err := wrk.BuildBlockFromTxs(ctx, args, types.Transactions{backend.newRandomTx(false)})
+	require.ErrorIs(t, err, ErrBuildInterrupted)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 func TestBuilder_Bid(t *testing.T) {
 	t.Parallel()
 
@@ -265,7 +1037,7 @@ func TestBuilder_Bid(t *testing.T) {
 	_, err = builder.Bid([48]byte{})
 	require.Error(t, err, "cannot create bid without block")
 
-	_, err = builder.BuildBlock()
+	_, err = builder.BuildBlock(context.Background())
 	require.NoError(t, err)
 
 	_, err = builder.Bid([48]byte{})
@@ -285,7 +1057,7 @@ func TestBuilder_Balance(t *testing.T) {
 
 	// make a random txn that consumes gas
 	tx1 := backend.newRandomTx(true)
-	_, err = builder.AddTransaction(tx1)
+	_, err = builder.AddTransaction(context.Background(), tx1)
 	require.NoError(t, err)
 
 	balance2 := builder.GetBalance(testBankAddress)
@@ -304,7 +1076,7 @@ func TestBuilder_Call(t *testing.T) {
 	require.NoError(t, err)
 	tx := backend.newCall(suaveExample1Addr, input)
 
-	simResult, err := builder.AddTransaction(tx)
+	simResult, err := builder.AddTransaction(context.Background(), tx)
 	require.NoError(t, err)
 	require.True(t, simResult.Success)
 
@@ -315,14 +1087,187 @@ func TestBuilder_Call(t *testing.T) {
 		To:   &suaveExample1Addr,
 		Data: &hexInput,
 	}
-	result, error := builder.Call(&args)
+	usage := builder.Usage()
+	result, error := builder.Call(context.Background(), &args, 0)
 	require.NoError(t, error)
 	result_new, error := suaveExample1Artifact.Abi.Unpack("counter", result)
 	require.NoError(t, error)
 	require.Equal(t, result_new[0].(*big.Int).Int64(), int64(1))
+
+	// the call counts in the usage of the builder, not as a transaction
+	require.Greater(t, builder.Usage().Gas, usage.Gas)
+	require.Equal(t, usage.Txs, builder.Usage().Txs)
+
+	// the gas of the call is capped
+	gas := hexutil.Uint64(1_000_000)
+	args.Gas = &gas
+	_, error = builder.Call(context.Background(), &args, params.TxGas)
+	require.ErrorIs(t, error, core.ErrIntrinsicGas)
+}
+
+// newBenchBuilder returns a builder holding n transactions, so that the state
+// of the environment has something to copy.
+func newBenchBuilder(b *testing.B, n int) (*Builder, *testWorkerBackend) {
+	config, backend := newMockBuilderConfig(b)
+	builder, err := NewBuilder(config, &BuilderArgs{})
+	require.NoError(b, err)
+
+	txs := make(types.Transactions, n)
+	for i := range txs {
+		txs[i] = backend.newRandomTxWithNonce(uint64(i))
+	}
+	res, err := builder.AddTransactions(context.Background(), txs)
+	require.NoError(b, err)
+	require.True(b, res[n-1].Success)
+	return builder, backend
+}
+
+// copyCheckpoint returns a checkpoint restoring a copy of the environment, the
+// way the environment was rolled back before the journal checkpoints.
+func copyCheckpoint(env *environment) *envCheckpoint {
+	return &envCheckpoint{env: env, copy: env.copy()}
+}
+
+// addTransactionsOnCopy is AddTransactions rolling back on a copy.
+func (b *Builder) addTransactionsOnCopy(txns types.Transactions) {
+	cp := copyCheckpoint(b.env)
+	for _, txn := range txns {
+		if _, _, err := b.addTransaction(txn, b.env); err != nil {
+			cp.revert()
+			return
+		}
+	}
+	cp.discard()
+}
+
+// addBundlesOnCopy is AddBundles rolling back on a copy.
+func (b *Builder) addBundlesOnCopy(bundles []*suavextypes.Bundle) {
+	cp := copyCheckpoint(b.env)
+	for _, bundle := range bundles {
+		result, tracer, err := b.addBundle(bundle, b.env)
+		if err != nil {
+			cp.revert()
+			return
+		}
+		b.bundleAccess[result.Hash] = tracer
+	}
+	cp.discard()
+}
+
+// callOnCopy is Call executing on a copy of the state instead of reverting
+// to a snapshot.
+func (b *Builder) callOnCopy(args *ethapi.TransactionArgs) ([]byte, error) {
+	if err := args.CallDefaults(b.env.header.GasLimit, common.Big0, b.wrk.chainConfig.ChainID); err != nil {
+		return nil, err
+	}
+	msg := args.ToMessage(common.Big0)
+	blockContext := core.NewEVMBlockContext(b.env.header, &ChainContextDummy{}, &common.MaxAddress)
+	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(msg), b.env.state.Copy(), b.wrk.chainConfig, vm.Config{NoBaseFee: true})
+
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err != nil {
+		return nil, err
+	}
+	return result.ReturnData, nil
+}
+
+// The rollback benchmarks add a batch failing on its last transaction on top
+// of a builder holding 100 transactions, the batch is rolled back every time.
+
+func BenchmarkBuilder_AddTransactionsRollback(b *testing.B) {
+	run := func(b *testing.B, add func(*Builder, types.Transactions)) {
+		builder, backend := newBenchBuilder(b, 100)
+		txs := make(types.Transactions, 0, 11)
+		for i := 100; i < 110; i++ {
+			txs = append(txs, backend.newRandomTxWithNonce(uint64(i)))
+		}
+		txs = append(txs, backend.newRandomTxWithNonce(1000)) // nonce too high
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			add(builder, txs)
+		}
+		b.StopTimer()
+		require.Len(b, builder.env.txs, 100)
+	}
+	b.Run("checkpoint", func(b *testing.B) {
+		run(b, func(builder *Builder, txs types.Transactions) {
+			builder.AddTransactions(context.Background(), txs)
+		})
+	})
+	b.Run("copy", func(b *testing.B) {
+		run(b, (*Builder).addTransactionsOnCopy)
+	})
+}
+
+func BenchmarkBuilder_AddBundlesRollback(b *testing.B) {
+	run := func(b *testing.B, add func(*Builder, []*suavextypes.Bundle)) {
+		builder, backend := newBenchBuilder(b, 100)
+		bundles := make([]*suavextypes.Bundle, 0, 6)
+		for i := 100; i < 110; i += 2 {
+			bundles = append(bundles, &suavextypes.Bundle{
+				Txs: types.Transactions{backend.newRandomTxWithNonce(uint64(i)), backend.newRandomTxWithNonce(uint64(i + 1))},
+			})
+		}
+		bundles = append(bundles, &suavextypes.Bundle{
+			Txs: types.Transactions{backend.newRandomTxWithNonce(1000)}, // nonce too high
+		})
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			add(builder, bundles)
+		}
+		b.StopTimer()
+		require.Len(b, builder.env.txs, 100)
+	}
+	b.Run("checkpoint", func(b *testing.B) {
+		run(b, func(builder *Builder, bundles []*suavextypes.Bundle) {
+			builder.AddBundles(context.Background(), bundles)
+		})
+	})
+	b.Run("copy", func(b *testing.B) {
+		run(b, (*Builder).addBundlesOnCopy)
+	})
+}
+
+func BenchmarkBuilder_Call(b *testing.B) {
+	run := func(b *testing.B, call func(*Builder, *ethapi.TransactionArgs) ([]byte, error)) {
+		builder, _ := newBenchBuilder(b, 100)
+		input, err := suaveExample1Artifact.Abi.Pack("increment")
+		require.NoError(b, err)
+		gasPrice := big.NewInt(10 * params.InitialBaseFee)
+		tx, err := types.SignTx(types.NewTransaction(100, suaveExample1Addr, big.NewInt(0), 1000000, gasPrice, input), types.HomesteadSigner{}, testBankKey)
+		require.NoError(b, err)
+		res, err := builder.AddTransaction(context.Background(), tx)
+		require.NoError(b, err)
+		require.True(b, res.Success)
+
+		// the call changes the state, which is reverted
+		data := hexutil.Bytes(input)
+		args := ethapi.TransactionArgs{To: &suaveExample1Addr, Data: &data}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			args := args
+			if _, err := call(builder, &args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("snapshot", func(b *testing.B) {
+		run(b, func(builder *Builder, args *ethapi.TransactionArgs) ([]byte, error) {
+			return builder.Call(context.Background(), args, 0)
+		})
+	})
+	b.Run("copy", func(b *testing.B) {
+		run(b, (*Builder).callOnCopy)
+	})
 }
 
-func newMockBuilderConfig(t *testing.T) (*BuilderConfig, *testWorkerBackend) {
+func newMockBuilderConfig(t testing.TB) (*BuilderConfig, *testWorkerBackend) {
 	var (
 		db     = rawdb.NewMemoryDatabase()
 		config = *params.AllCliqueProtocolChanges
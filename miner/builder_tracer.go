@@ -0,0 +1,171 @@
+package miner
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+// accessSet is a set of accounts and storage slots.
+type accessSet struct {
+	accounts map[common.Address]struct{}
+	storage  map[common.Address]map[common.Hash]struct{}
+}
+
+func newAccessSet() *accessSet {
+	return &accessSet{
+		accounts: make(map[common.Address]struct{}),
+		storage:  make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (a *accessSet) addAccount(addr common.Address) {
+	a.accounts[addr] = struct{}{}
+}
+
+func (a *accessSet) addSlot(addr common.Address, slot common.Hash) {
+	if _, ok := a.storage[addr]; !ok {
+		a.storage[addr] = make(map[common.Hash]struct{})
+	}
+	a.storage[addr][slot] = struct{}{}
+}
+
+// merge adds all the entries of other into the set.
+func (a *accessSet) merge(other *accessSet) {
+	for addr := range other.accounts {
+		a.addAccount(addr)
+	}
+	for addr, slots := range other.storage {
+		for slot := range slots {
+			a.addSlot(addr, slot)
+		}
+	}
+}
+
+// overlaps returns the accounts and storage slots present in both sets.
+func (a *accessSet) overlaps(other *accessSet) *accessSet {
+	res := newAccessSet()
+	for addr := range a.accounts {
+		if _, ok := other.accounts[addr]; ok {
+			res.addAccount(addr)
+		}
+	}
+	for addr, slots := range a.storage {
+		for slot := range slots {
+			if _, ok := other.storage[addr][slot]; ok {
+				res.addSlot(addr, slot)
+			}
+		}
+	}
+	return res
+}
+
+func (a *accessSet) empty() bool {
+	return len(a.accounts) == 0 && len(a.storage) == 0
+}
+
+// toStateAccess returns the set in a deterministic order.
+func (a *accessSet) toStateAccess() *suavextypes.StateAccess {
+	res := &suavextypes.StateAccess{
+		Accounts: make([]common.Address, 0, len(a.accounts)),
+		Storage:  make(map[common.Address][]common.Hash, len(a.storage)),
+	}
+	for addr := range a.accounts {
+		res.Accounts = append(res.Accounts, addr)
+	}
+	sort.Slice(res.Accounts, func(i, j int) bool {
+		return bytes.Compare(res.Accounts[i][:], res.Accounts[j][:]) < 0
+	})
+	for addr, slots := range a.storage {
+		keys := make([]common.Hash, 0, len(slots))
+		for slot := range slots {
+			keys = append(keys, slot)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i][:], keys[j][:]) < 0
+		})
+		res.Storage[addr] = keys
+	}
+	return res
+}
+
+// accessTracer records the accounts and storage slots read and written
+// while executing transactions on top of an environment.
+type accessTracer struct {
+	reads  *accessSet
+	writes *accessSet
+}
+
+func newAccessTracer() *accessTracer {
+	return &accessTracer{
+		reads:  newAccessSet(),
+		writes: newAccessSet(),
+	}
+}
+
+// merge adds the reads and writes of other into the tracer.
+func (a *accessTracer) merge(other *accessTracer) {
+	a.reads.merge(other.reads)
+	a.writes.merge(other.writes)
+}
+
+// conflicts returns the state written by one of the tracers and read or
+// written by the other one.
+func (a *accessTracer) conflicts(other *accessTracer) *accessSet {
+	res := a.writes.overlaps(other.writes)
+	res.merge(a.writes.overlaps(other.reads))
+	res.merge(a.reads.overlaps(other.writes))
+	return res
+}
+
+// Hooks returns the tracing hooks that feed the tracer.
+func (a *accessTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: func(_ *tracing.VMContext, tx *types.Transaction, from common.Address) {
+			a.reads.addAccount(from)
+			if to := tx.To(); to != nil {
+				a.reads.addAccount(*to)
+			}
+		},
+		OnEnter: func(_ int, _ byte, _ common.Address, to common.Address, _ []byte, _ uint64, _ *big.Int) {
+			a.reads.addAccount(to)
+		},
+		OnOpcode: func(_ uint64, op byte, _, _ uint64, scope tracing.OpContext, _ []byte, _ int, _ error) {
+			stack := scope.StackData()
+			if len(stack) == 0 {
+				return
+			}
+			top := stack[len(stack)-1]
+
+			switch vm.OpCode(op) {
+			case vm.SLOAD, vm.SSTORE:
+				a.reads.addSlot(scope.Address(), common.Hash(top.Bytes32()))
+			case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.EXTCODECOPY:
+				a.reads.addAccount(common.Address(top.Bytes20()))
+			}
+		},
+		OnBalanceChange: func(addr common.Address, _, _ *big.Int, reason tracing.BalanceChangeReason) {
+			// Every transaction pays the coinbase, ignore the fee payment
+			// so that it does not make every pair of transactions conflict.
+			if reason == tracing.BalanceIncreaseRewardTransactionFee {
+				return
+			}
+			a.writes.addAccount(addr)
+		},
+		OnNonceChange: func(addr common.Address, _, _ uint64) {
+			a.writes.addAccount(addr)
+		},
+		OnCodeChange: func(addr common.Address, _ common.Hash, _ []byte, _ common.Hash, _ []byte) {
+			a.writes.addAccount(addr)
+		},
+		OnStorageChange: func(addr common.Address, slot common.Hash, _, _ common.Hash) {
+			a.writes.addSlot(addr, slot)
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package miner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BuilderUsage is the resources spent by a builder on the transactions it
+// applied, including the ones simulated on copies of its state, and on calls.
+type BuilderUsage struct {
+	Gas         uint64        // gas used by the transactions
+	Txs         uint64        // transactions applied, whether they succeeded or not
+	Bundles     uint64        // bundles applied, whether they succeeded or not
+	CPUTime     time.Duration // time spent applying the transactions
+	StateCopies uint64        // copies made of the state
+}
+
+// usageTracker counts the resources spent by a builder. It is shared by the
+// environments of the builder and the copies made from them, which can be used
+// concurrently. The methods of a nil tracker do nothing.
+type usageTracker struct {
+	gas         atomic.Uint64
+	txs         atomic.Uint64
+	bundles     atomic.Uint64
+	cpuTime     atomic.Int64
+	stateCopies atomic.Uint64
+}
+
+func (u *usageTracker) addTransaction(receipt *types.Receipt, elapsed time.Duration) {
+	if u == nil {
+		return
+	}
+	if receipt != nil {
+		u.gas.Add(receipt.GasUsed)
+	}
+	u.txs.Add(1)
+	u.cpuTime.Add(int64(elapsed))
+}
+
+// addCall counts a call, which is not a transaction of the builder but spends
+// gas and time all the same.
+func (u *usageTracker) addCall(gasUsed uint64, elapsed time.Duration) {
+	if u == nil {
+		return
+	}
+	u.gas.Add(gasUsed)
+	u.cpuTime.Add(int64(elapsed))
+}
+
+func (u *usageTracker) addBundle() {
+	if u != nil {
+		u.bundles.Add(1)
+	}
+}
+
+func (u *usageTracker) addStateCopy() {
+	if u != nil {
+		u.stateCopies.Add(1)
+	}
+}
+
+func (u *usageTracker) usage() BuilderUsage {
+	if u == nil {
+		return BuilderUsage{}
+	}
+	return BuilderUsage{
+		Gas:         u.gas.Load(),
+		Txs:         u.txs.Load(),
+		Bundles:     u.bundles.Load(),
+		CPUTime:     time.Duration(u.cpuTime.Load()),
+		StateCopies: u.stateCopies.Load(),
+	}
+}
+
+// Usage returns the resources spent by the builder since it was created.
+func (b *Builder) Usage() BuilderUsage {
+	return b.usage.usage()
+}
@@ -0,0 +1,175 @@
+package miner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+var (
+	ErrDeniedAddress = suavextypes.ErrDeniedAddress
+
+	errNoDenyListFile = errors.New("no deny list file configured")
+)
+
+// DenyList is the set of addresses that the built blocks must not touch. A
+// transaction is rejected if its sender or recipient is denied or if any
+// denied address is accessed while executing it. The list can be replaced at
+// runtime.
+type DenyList struct {
+	path string
+
+	mu    sync.RWMutex
+	addrs map[common.Address]struct{} // never modified once set, replaced as a whole
+}
+
+// NewDenyList creates a deny list loaded from the given JSON file, which holds
+// an array of addresses. An empty path creates an empty list.
+func NewDenyList(path string) (*DenyList, error) {
+	d := &DenyList{path: path}
+	if path == "" {
+		return d, nil
+	}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload replaces the list with the current content of its file.
+func (d *DenyList) Reload() error {
+	if d.path == "" {
+		return errNoDenyListFile
+	}
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+	var addrs []common.Address
+	if err := json.Unmarshal(data, &addrs); err != nil {
+		return fmt.Errorf("invalid deny list file %s: %w", d.path, err)
+	}
+	d.Set(addrs)
+	return nil
+}
+
+// Set replaces the list with the given addresses.
+func (d *DenyList) Set(addrs []common.Address) {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+
+	d.mu.Lock()
+	d.addrs = set
+	d.mu.Unlock()
+
+	log.Info("Deny list updated", "addresses", len(set))
+}
+
+// Addresses returns the addresses in the list.
+func (d *DenyList) Addresses() []common.Address {
+	set := d.set()
+	addrs := make([]common.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// set returns the current set of denied addresses, which must not be modified.
+func (d *DenyList) set() map[common.Address]struct{} {
+	if d == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.addrs
+}
+
+// checkTxAddresses checks the sender and the recipient of the transaction.
+func checkTxAddresses(signer types.Signer, tx *types.Transaction, denied map[common.Address]struct{}) error {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return err
+	}
+	if _, ok := denied[from]; ok {
+		return fmt.Errorf("%w: sender %s", ErrDeniedAddress, from)
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := denied[*to]; ok {
+			return fmt.Errorf("%w: recipient %s", ErrDeniedAddress, *to)
+		}
+	}
+	return nil
+}
+
+// executionAbort is raised by the hooks to stop the execution of a transaction
+// before its state changes are finalised, so they can be reverted.
+type executionAbort struct {
+	err error
+}
+
+// applyWithAbort applies a transaction and turns an abort raised by the hooks
+// into an error.
+func applyWithAbort(apply func() (*types.Receipt, error)) (receipt *types.Receipt, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			abort, ok := r.(executionAbort)
+			if !ok {
+				panic(r)
+			}
+			receipt, err = nil, abort.err
+		}
+	}()
+	return apply()
+}
+
+// deniedAddressHooks returns the given hooks extended to abort the execution
+// as soon as a denied address is reached by a call or accessed by an opcode.
+func deniedAddressHooks(denied map[common.Address]struct{}, inner *tracing.Hooks) *tracing.Hooks {
+	if len(denied) == 0 {
+		return inner
+	}
+	hooks := new(tracing.Hooks)
+	if inner != nil {
+		*hooks = *inner
+	}
+	check := func(addr common.Address) {
+		if _, ok := denied[addr]; ok {
+			panic(executionAbort{fmt.Errorf("%w: %s accessed during execution", ErrDeniedAddress, addr)})
+		}
+	}
+
+	onEnter := hooks.OnEnter
+	hooks.OnEnter = func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+		check(to)
+		if onEnter != nil {
+			onEnter(depth, typ, from, to, input, gas, value)
+		}
+	}
+
+	onOpcode := hooks.OnOpcode
+	hooks.OnOpcode = func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+		switch vm.OpCode(op) {
+		case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.EXTCODECOPY:
+			if stack := scope.StackData(); len(stack) > 0 {
+				check(common.Address(stack[len(stack)-1].Bytes20()))
+			}
+		}
+		if onOpcode != nil {
+			onOpcode(pc, op, gas, cost, scope, rData, depth, err)
+		}
+	}
+	return hooks
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	suavextypes "github.com/ethereum/go-ethereum/suave/builder/api"
 )
 
 // Backend wraps all methods required for mining. Only full node is capable
@@ -49,6 +50,17 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+
+	// --- SUAVE SPECIFIC ---
+	DenyList      string   `toml:",omitempty"` // JSON file with the addresses built blocks must not touch
+	SessionAdmins []string `toml:",omitempty"` // RPC clients allowed to access the builder sessions of every client
+
+	// Resources every builder session, and the sessions of every client
+	// together over ClientBudgetWindow, can spend on simulations. Zero means
+	// no limit.
+	SessionBudget      suavextypes.Budget `toml:",omitempty"`
+	ClientBudget       suavextypes.Budget `toml:",omitempty"`
+	ClientBudgetWindow time.Duration      `toml:",omitempty"`
 }
 
 // DefaultConfig contains default settings for miner.
@@ -74,6 +86,11 @@ type Miner struct {
 	chain       *core.BlockChain
 	pending     *pending
 	pendingMu   sync.Mutex // Lock protects the pending block
+
+	// --- SUAVE SPECIFIC ---
+	bundles  BundleSource // Optional source of stored bundles for buildBlockFromBundles
+	denyList *DenyList    // Optional addresses the built blocks must not touch
+	built    *builtChain  // Optional blocks built but not imported that the work extends
 }
 
 // New creates a new miner with provided config.
@@ -165,10 +182,27 @@ func (miner *Miner) getPending() *newPayloadResult {
 	return ret
 }
 
-func (miner *Miner) BuildBlockFromTxs(ctx context.Context, buildArgs *types.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, []*types.BlobTxSidecar, error) {
+func (miner *Miner) BuildBlockFromTxs(ctx context.Context, buildArgs *types.BuildBlockArgs, txs types.Transactions) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error) {
 	return miner.buildBlockFromTxs(ctx, buildArgs, txs)
 }
 
-func (miner *Miner) BuildBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, []*types.BlobTxSidecar, error) {
+func (miner *Miner) BuildBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error) {
 	return miner.buildBlockFromBundles(ctx, buildArgs, bundles)
 }
+
+// SetBundleSource sets the source of stored bundles that are added to the
+// blocks built from bundles.
+func (miner *Miner) SetBundleSource(bundles BundleSource) {
+	miner.bundles = bundles
+}
+
+// SetDenyList sets the addresses that the built blocks must not touch, the
+// local payloads as well as the blocks of the builders.
+func (miner *Miner) SetDenyList(denyList *DenyList) {
+	miner.denyList = denyList
+}
+
+// DenyList returns the addresses that the built blocks must not touch.
+func (miner *Miner) DenyList() *DenyList {
+	return miner.denyList
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
@@ -105,7 +106,7 @@ type testWorkerBackend struct {
 	genesis *core.Genesis
 }
 
-func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, n int) *testWorkerBackend {
+func newTestWorkerBackend(t testing.TB, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, n int) *testWorkerBackend {
 	var gspec = &core.Genesis{
 		Config: chainConfig,
 		Alloc:  types.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
@@ -124,6 +125,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 			return crypto.Sign(crypto.Keccak256(data), testBankKey)
 		})
 	case *ethash.Ethash:
+	case *beacon.Beacon:
 	default:
 		t.Fatalf("unexpected consensus engine type: %T", engine)
 	}
@@ -145,7 +147,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 func (b *testWorkerBackend) BlockChain() *core.BlockChain { return b.chain }
 func (b *testWorkerBackend) TxPool() *txpool.TxPool       { return b.txPool }
 
-func newTestWorker(t *testing.T, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, blocks int) (*Miner, *testWorkerBackend) {
+func newTestWorker(t testing.TB, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, blocks int) (*Miner, *testWorkerBackend) {
 	backend := newTestWorkerBackend(t, chainConfig, engine, db, blocks)
 	backend.txPool.Add(pendingTxs, true, false)
 	w := New(backend, testConfig, engine)
@@ -203,6 +205,44 @@ func TestBuildPayload(t *testing.T) {
 	}
 }
 
+// --- SUAVE SPECIFIC ---
+
+func TestBuildPayloadDenyList(t *testing.T) {
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	denyList, _ := NewDenyList("")
+	denyList.Set([]common.Address{testUserAddress})
+	w.SetDenyList(denyList)
+	if err := b.txPool.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pending transaction sent to the denied address is skipped
+	res := w.generateWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: b.chain.CurrentBlock().Hash(),
+	})
+	if res.err != nil {
+		t.Fatalf("Failed to generate work %v", res.err)
+	}
+	if len(res.block.Transactions()) != 0 {
+		t.Fatalf("Local payload includes %d transactions to a denied address", len(res.block.Transactions()))
+	}
+
+	denyList.Set(nil)
+	res = w.generateWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: b.chain.CurrentBlock().Hash(),
+	})
+	if res.err != nil {
+		t.Fatalf("Failed to generate work %v", res.err)
+	}
+	if len(res.block.Transactions()) != len(pendingTxs) {
+		t.Fatalf("Unexpected transaction set, have %d want %d", len(res.block.Transactions()), len(pendingTxs))
+	}
+}
+
+// --- END OF SUAVE SPECIFIC ---
+
 func TestPayloadId(t *testing.T) {
 	t.Parallel()
 	ids := make(map[string]int)
@@ -0,0 +1,109 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// profitTracker accounts for the value explicitly transferred to the fee
+// recipient of a block by its transactions. Unlike a balance difference, it is
+// not affected by the transactions sent by the fee recipient, by the value it
+// receives from withdrawals or by the contracts spending its balance.
+type profitTracker struct {
+	feeRecipient common.Address
+	transfers    *big.Int
+}
+
+func newProfitTracker(feeRecipient common.Address) *profitTracker {
+	return &profitTracker{
+		feeRecipient: feeRecipient,
+		transfers:    new(big.Int),
+	}
+}
+
+func (p *profitTracker) copy() *profitTracker {
+	return &profitTracker{
+		feeRecipient: p.feeRecipient,
+		transfers:    new(big.Int).Set(p.transfers),
+	}
+}
+
+// txTransfers collects the transfers to the fee recipient made while executing
+// a single transaction. Every call frame holds the value received within it,
+// which is dropped if the frame reverts.
+type txTransfers struct {
+	feeRecipient common.Address
+	frames       []*big.Int
+	total        *big.Int
+}
+
+func newTxTransfers(feeRecipient common.Address) *txTransfers {
+	return &txTransfers{
+		feeRecipient: feeRecipient,
+		total:        new(big.Int),
+	}
+}
+
+// hooks returns the given hooks extended to collect the transfers.
+func (t *txTransfers) hooks(inner *tracing.Hooks) *tracing.Hooks {
+	hooks := new(tracing.Hooks)
+	if inner != nil {
+		*hooks = *inner
+	}
+
+	onEnter := hooks.OnEnter
+	hooks.OnEnter = func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+		received := new(big.Int)
+		if to == t.feeRecipient && from != t.feeRecipient && value != nil {
+			received.Set(value)
+		}
+		t.frames = append(t.frames, received)
+		if onEnter != nil {
+			onEnter(depth, typ, from, to, input, gas, value)
+		}
+	}
+
+	onExit := hooks.OnExit
+	hooks.OnExit = func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+		if n := len(t.frames); n > 0 {
+			received := t.frames[n-1]
+			t.frames = t.frames[:n-1]
+			if !reverted {
+				if n > 1 {
+					t.frames[n-2].Add(t.frames[n-2], received)
+				} else {
+					t.total.Add(t.total, received)
+				}
+			}
+		}
+		if onExit != nil {
+			onExit(depth, output, gasUsed, err, reverted)
+		}
+	}
+	return hooks
+}
+
+// blockProfit returns the value received by the fee recipient from the
+// transactions of the environment. The priority fees only count if the fee
+// recipient is the coinbase of the block.
+func (env *environment) blockProfit() *types.BlockProfit {
+	fees := new(big.Int)
+	if env.profit != nil && env.coinbase == env.profit.feeRecipient {
+		for i, tx := range env.txs {
+			tip, _ := tx.EffectiveGasTip(env.header.BaseFee)
+			fees.Add(fees, new(big.Int).Mul(new(big.Int).SetUint64(env.receipts[i].GasUsed), tip))
+		}
+	}
+	transfers := new(big.Int)
+	if env.profit != nil {
+		transfers.Set(env.profit.transfers)
+	}
+	return &types.BlockProfit{
+		PriorityFees:      (*hexutil.Big)(fees),
+		CoinbaseTransfers: (*hexutil.Big)(transfers),
+	}
+}
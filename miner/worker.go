@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -28,12 +29,15 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -56,6 +60,15 @@ type environment struct {
 	receipts []*types.Receipt
 	sidecars []*types.BlobTxSidecar
 	blobs    int
+
+	// --- SUAVE SPECIFIC ---
+	ctx     context.Context // optional context aborting the transactions being applied
+	tracer  *tracing.Hooks  // optional hooks invoked while applying transactions
+	maxTxs  int             // stop filling the block once it holds this many transactions
+	profit  *profitTracker  // optional accounting of the transfers to the fee recipient
+	bundles []bundleSpan    // bundles applied by builders, in order
+	usage   *usageTracker   // optional accounting of the resources spent by builders
+	denied  *DenyList       // optional addresses the transactions must not touch
 }
 
 const (
@@ -87,7 +100,8 @@ type generateParams struct {
 	noTxs       bool              // Flag whether an empty block without any transaction is expected
 
 	// --- SUAVE SPECIFIC ---
-	extra []byte
+	extra    []byte
+	gasLimit uint64 // overrides the gas limit derived from the parent if set
 }
 
 // generateWork generates a sealing block based on the given parameters.
@@ -131,7 +145,10 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 
 	// Find the parent block for sealing task
 	parent := miner.chain.CurrentBlock()
-	if genParams.parentHash != (common.Hash{}) {
+	// --- SUAVE SPECIFIC ---
+	if miner.built != nil {
+		parent = miner.built.head()
+	} else if genParams.parentHash != (common.Hash{}) {
 		block := miner.chain.GetBlockByHash(genParams.parentHash)
 		if block == nil {
 			return nil, fmt.Errorf("missing parent")
@@ -178,9 +195,13 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 			header.GasLimit = core.CalcGasLimit(parentGasLimit, miner.config.GasCeil)
 		}
 	}
+	// --- SUAVE SPECIFIC ---
+	if genParams.gasLimit != 0 {
+		header.GasLimit = genParams.gasLimit
+	}
 	// Run the consensus preparation with the default or customized consensus engine.
 	// Note that the `header.Time` may be changed.
-	if err := miner.engine.Prepare(miner.chain, header); err != nil {
+	if err := miner.engine.Prepare(miner.headerReader(), header); err != nil {
 		log.Error("Failed to prepare header for sealing", "err", err)
 		return nil, err
 	}
@@ -206,10 +227,12 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 		return nil, err
 	}
 	if header.ParentBeaconRoot != nil {
-		context := core.NewEVMBlockContext(header, miner.chain, nil)
+		context := core.NewEVMBlockContext(header, miner.chainContext(), nil)
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, miner.chainConfig, vm.Config{})
 		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
 	}
+	// --- SUAVE SPECIFIC ---
+	env.denied = miner.denyList
 	return env, nil
 }
 
@@ -217,6 +240,15 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 func (miner *Miner) makeEnv(parent *types.Header, header *types.Header, coinbase common.Address) (*environment, error) {
 	// Retrieve the parent state to execute on top and start a prefetcher for
 	// the miner to speed block sealing up a bit.
+	// --- SUAVE SPECIFIC ---
+	if miner.built != nil {
+		return &environment{
+			signer:   types.MakeSigner(miner.chainConfig, header.Number, header.Time),
+			state:    miner.built.state.Copy(),
+			coinbase: coinbase,
+			header:   header,
+		}, nil
+	}
 	state, err := miner.chain.StateAt(parent.Root)
 	if err != nil {
 		return nil, err
@@ -275,12 +307,41 @@ func (miner *Miner) applyTransaction(env *environment, tx *types.Transaction) (*
 		snap = env.state.Snapshot()
 		gp   = env.gasPool.Gas()
 	)
-	receipt, err := core.ApplyTransaction(miner.chainConfig, miner.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, vm.Config{})
+	// --- SUAVE SPECIFIC ---
+	denied := env.denied.set()
+	if len(denied) > 0 {
+		if err := checkTxAddresses(env.signer, tx, denied); err != nil {
+			return nil, err
+		}
+	}
+	hooks := env.tracer
+	var transfers *txTransfers
+	if env.profit != nil {
+		transfers = newTxTransfers(env.profit.feeRecipient)
+		hooks = transfers.hooks(hooks)
+	}
+	span := env.startSpan("miner.applyTransaction")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("tx.hash", tx.Hash().Hex()))
+	}
+	start := time.Now()
+	receipt, err := applyWithAbort(func() (*types.Receipt, error) {
+		return miner.applyTransactionWithContext(env, tx, deniedAddressHooks(denied, hooks))
+	})
+	env.usage.addTransaction(receipt, time.Since(start))
+	if receipt != nil {
+		span.SetAttributes(attribute.Int64("tx.gasUsed", int64(receipt.GasUsed)), attribute.Int64("tx.status", int64(receipt.Status)))
+	}
+	telemetry.End(span, err)
 	if err != nil {
 		env.state.RevertToSnapshot(snap)
 		env.gasPool.SetGas(gp)
+		return nil, err
+	}
+	if transfers != nil {
+		env.profit.transfers.Add(env.profit.transfers, transfers.total)
 	}
-	return receipt, err
+	return receipt, nil
 }
 
 func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *transactionsByPriceAndNonce, interrupt *atomic.Int32) error {
@@ -295,6 +356,11 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 				return signalToErr(signal)
 			}
 		}
+		// --- SUAVE SPECIFIC ---
+		if env.maxTxs != 0 && env.tcount >= env.maxTxs {
+			log.Trace("Transaction count limit reached", "limit", env.maxTxs)
+			break
+		}
 		// If we don't have enough gas for any further transactions then we're done.
 		if env.gasPool.Gas() < params.TxGas {
 			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
@@ -373,6 +439,10 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			txs.Shift()
 
+		// --- SUAVE SPECIFIC ---
+		case errors.Is(err, ErrBuildInterrupted):
+			return err
+
 		default:
 			// Transaction is regarded as invalid, drop all consecutive transactions from
 			// the same sender because of `nonce-too-high` clause.
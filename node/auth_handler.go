@@ -0,0 +1,86 @@
+package node
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// apiKeyHeader is the header of the API key of the requests.
+const apiKeyHeader = "X-API-Key"
+
+type authHandler struct {
+	auth *rpcAuth
+	next http.Handler
+}
+
+// newAuthHandler creates a http.Handler authenticating the clients with their
+// API key or with a JWT. The name of the API key or the subject of the JWT is
+// passed to the RPC handlers as the identity of the client, along with the
+// protected namespaces it is granted access to. The requests with an invalid
+// credential are rejected, the ones without credential are passed as is and
+// the RPC server rejects their calls to the protected namespaces.
+//
+// The holders of the JWT secret can claim any subject, the JWT only
+// authenticates the operator and its trusted services. The clients are told
+// apart by their API key.
+//
+// With a JWT secret, every request must carry a token signed with it.
+func newAuthHandler(jwtSecret []byte, auth *rpcAuth, next http.Handler) http.Handler {
+	handler := next
+	if auth != nil && (len(auth.jwtSecret) != 0 || len(auth.apiKeys) != 0) {
+		handler = &authHandler{auth: auth, next: next}
+	}
+	if len(jwtSecret) != 0 {
+		handler = newJWTHandler(jwtSecret, handler)
+	}
+	return handler
+}
+
+// ServeHTTP implements http.Handler
+func (handler *authHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
+	var (
+		subject string
+		byJWT   bool
+	)
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		name, ok := handler.client(key)
+		if !ok {
+			http.Error(out, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		subject = name
+	} else if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") && len(handler.auth.jwtSecret) != 0 {
+		secret := handler.auth.jwtSecret
+		claims, err := verifyJWT(strings.TrimPrefix(auth, "Bearer "), func(*jwt.Token) (interface{}, error) { return secret, nil })
+		if err != nil {
+			http.Error(out, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		subject, byJWT = claims.Subject, true
+	} else {
+		handler.next.ServeHTTP(out, r)
+		return
+	}
+	ctx := rpc.WithAuthSubject(r.Context(), subject)
+	ctx = rpc.WithAuthNamespaces(ctx, handler.auth.namespaces(subject, byJWT))
+	handler.next.ServeHTTP(out, r.WithContext(ctx))
+}
+
+// client returns the name of the client with the given API key. Every key is
+// compared in constant time so that the timing does not reveal the keys.
+func (handler *authHandler) client(key string) (string, bool) {
+	var (
+		name  string
+		found bool
+	)
+	for n, k := range handler.auth.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			name, found = n, true
+		}
+	}
+	return name, found
+}
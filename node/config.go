@@ -27,6 +27,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -211,6 +212,30 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// --- SUAVE SPECIFIC ---
+
+	// RPCJWTSecret is the path to the hex-encoded jwt secret authenticating the
+	// operator of the node and its trusted services on the HTTP and WebSocket
+	// endpoints. The subject of the token identifies the caller, but since any
+	// holder of the secret can sign a token for any subject, the secret must
+	// not be shared with the clients: they are identified by their RPCAPIKeys.
+	RPCJWTSecret string `toml:",omitempty"`
+
+	// RPCAPIKeys are the API keys of the clients of the HTTP and WebSocket
+	// endpoints by client name, sent in the X-API-Key header. The name
+	// identifies the client, every client must have its own key.
+	RPCAPIKeys map[string]string `toml:",omitempty"`
+
+	// RPCPolicies are the access policies of the namespaces of the HTTP,
+	// WebSocket and IPC endpoints. The namespaces without policy are open to
+	// every client without limit.
+	RPCPolicies []RPCPolicy `toml:",omitempty"`
+
+	// Tracing configures the export of the spans of the RPC requests. Only the
+	// calls of the configured namespaces are traced, suavex by default, and the
+	// spans are not recorded if it has no exporter.
+	Tracing telemetry.Config `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -17,10 +17,12 @@
 package node
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -43,10 +45,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 
 // ServeHTTP implements http.Handler
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
-	var (
-		strToken string
-		claims   jwt.RegisteredClaims
-	)
+	var strToken string
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
 	}
@@ -54,27 +53,41 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 		http.Error(out, "missing token", http.StatusUnauthorized)
 		return
 	}
+	// --- SUAVE SPECIFIC ---
+	claims, err := verifyJWT(strToken, handler.keyFunc)
+	if err != nil {
+		http.Error(out, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	r = r.WithContext(rpc.WithAuthSubject(r.Context(), claims.Subject))
+	handler.next.ServeHTTP(out, r)
+}
+
+// --- SUAVE SPECIFIC ---
+
+// verifyJWT returns the claims of a valid token.
+func verifyJWT(strToken string, keyFunc jwt.Keyfunc) (*jwt.RegisteredClaims, error) {
+	var claims jwt.RegisteredClaims
 	// We explicitly set only HS256 allowed, and also disables the
 	// claim-check: the RegisteredClaims internally requires 'iat' to
 	// be no later than 'now', but we allow for a bit of drift.
-	token, err := jwt.ParseWithClaims(strToken, &claims, handler.keyFunc,
+	token, err := jwt.ParseWithClaims(strToken, &claims, keyFunc,
 		jwt.WithValidMethods([]string{"HS256"}),
 		jwt.WithoutClaimsValidation())
 
 	switch {
 	case err != nil:
-		http.Error(out, err.Error(), http.StatusUnauthorized)
+		return nil, err
 	case !token.Valid:
-		http.Error(out, "invalid token", http.StatusUnauthorized)
+		return nil, errors.New("invalid token")
 	case !claims.VerifyExpiresAt(time.Now(), false): // optional
-		http.Error(out, "token is expired", http.StatusUnauthorized)
+		return nil, errors.New("token is expired")
 	case claims.IssuedAt == nil:
-		http.Error(out, "missing issued-at", http.StatusUnauthorized)
+		return nil, errors.New("missing issued-at")
 	case time.Since(claims.IssuedAt.Time) > jwtExpiryTimeout:
-		http.Error(out, "stale token", http.StatusUnauthorized)
+		return nil, errors.New("stale token")
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
-		http.Error(out, "future token", http.StatusUnauthorized)
-	default:
-		handler.next.ServeHTTP(out, r)
+		return nil, errors.New("future token")
 	}
+	return &claims, nil
 }
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -65,6 +66,9 @@ type Node struct {
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
 	databases map[*closeTrackingDB]struct{} // All open databases
+
+	// --- SUAVE SPECIFIC ---
+	tracer *telemetry.Tracer // exporter of the spans of the requests, if configured
 }
 
 const (
@@ -154,6 +158,14 @@ func New(conf *Config) (*Node, error) {
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
 
+	// --- SUAVE SPECIFIC ---
+	if conf.Tracing.Enabled() {
+		if node.tracer, err = telemetry.New(conf.Tracing); err != nil {
+			return nil, err
+		}
+		telemetry.SetTracer(node.tracer)
+	}
+
 	return node, nil
 }
 
@@ -248,6 +260,14 @@ func (n *Node) doClose(errs []error) error {
 	// Release instance directory lock.
 	n.closeDataDir()
 
+	// --- SUAVE SPECIFIC ---
+	if n.tracer != nil {
+		telemetry.SetTracer(nil)
+		if err := n.tracer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	// Unblock n.Wait.
 	close(n.stop)
 
@@ -399,9 +419,15 @@ func (n *Node) startRPC() error {
 		return err
 	}
 
+	// --- SUAVE SPECIFIC ---
+	auth, err := newRPCAuth(n.config)
+	if err != nil {
+		return err
+	}
+
 	// Configure IPC.
 	if n.ipc.endpoint != "" {
-		if err := n.ipc.start(apis); err != nil {
+		if err := n.ipc.start(apis, auth); err != nil {
 			return err
 		}
 	}
@@ -413,6 +439,7 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		auth:                   auth, // SUAVE SPECIFIC
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RPCPolicy is the access policy of a set of RPC namespaces. The limits apply
+// to every client separately: the authenticated clients are told apart by the
+// subject of their token or the name of their API key, the other ones by their
+// address, and the clients of the IPC endpoint by their connection.
+type RPCPolicy struct {
+	Namespaces []string
+
+	// Protected restricts the namespaces to the clients authenticated with the
+	// RPCJWTSecret or the RPCAPIKeys of the node. Clients restricts them further
+	// to the named API keys, the holders of the JWT secret are always granted
+	// access.
+	Protected bool     `toml:",omitempty"`
+	Clients   []string `toml:",omitempty"`
+
+	// RequestsPerSecond limits the rate of the calls of a client, with bursts
+	// of up to RequestBurst calls. No limit if zero.
+	RequestsPerSecond float64 `toml:",omitempty"`
+	RequestBurst      int     `toml:",omitempty"`
+
+	// MaxConcurrentRequests limits the calls of a client in progress at once.
+	// No limit if zero.
+	MaxConcurrentRequests int `toml:",omitempty"`
+}
+
+// rpcAuth holds the credentials of the clients of the HTTP and WebSocket
+// endpoints and the policies of the namespaces of every endpoint.
+type rpcAuth struct {
+	jwtSecret []byte            // optional JWT secret
+	apiKeys   map[string]string // optional API keys by client name
+	policies  []RPCPolicy
+}
+
+// newRPCAuth loads the JWT secret of the node and checks its policies.
+func newRPCAuth(config *Config) (*rpcAuth, error) {
+	auth := &rpcAuth{apiKeys: config.RPCAPIKeys, policies: config.RPCPolicies}
+	if config.RPCJWTSecret != "" {
+		secret, err := ObtainJWTSecret(config.RPCJWTSecret)
+		if err != nil {
+			return nil, err
+		}
+		auth.jwtSecret = secret
+	}
+	for _, p := range auth.policies {
+		if len(p.Namespaces) == 0 {
+			return nil, errors.New("RPC policy without namespace")
+		}
+		if len(p.Clients) != 0 && !p.Protected {
+			return nil, fmt.Errorf("RPC policy of %v: clients of a public policy", p.Namespaces)
+		}
+		for _, client := range p.Clients {
+			if _, ok := auth.apiKeys[client]; !ok {
+				return nil, fmt.Errorf("RPC policy of %v: client %q without API key", p.Namespaces, client)
+			}
+		}
+	}
+	return auth, nil
+}
+
+// namespaces returns the protected namespaces an authenticated client is
+// granted access to. The holders of the JWT secret are granted every one.
+func (a *rpcAuth) namespaces(client string, jwt bool) []string {
+	namespaces := []string{}
+	for _, p := range a.policies {
+		if p.Protected && (jwt || len(p.Clients) == 0 || slices.Contains(p.Clients, client)) {
+			namespaces = append(namespaces, p.Namespaces...)
+		}
+	}
+	return namespaces
+}
+
+// serverPolicies returns the policies enforced by the RPC servers. The clients
+// of the IPC endpoint cannot send credentials, and are trusted with every
+// namespace since they have access to the local socket: only the limits apply
+// to them.
+func (a *rpcAuth) serverPolicies(ipc bool) []*rpc.Policy {
+	if a == nil {
+		return nil
+	}
+	var res []*rpc.Policy
+	for _, p := range a.policies {
+		res = append(res, &rpc.Policy{
+			Namespaces:            p.Namespaces,
+			Protected:             p.Protected && !ipc,
+			RequestsPerSecond:     p.RequestsPerSecond,
+			RequestBurst:          p.RequestBurst,
+			MaxConcurrentRequests: p.MaxConcurrentRequests,
+		})
+	}
+	return res
+}
@@ -54,7 +54,8 @@ type wsConfig struct {
 }
 
 type rpcEndpointConfig struct {
-	jwtSecret              []byte // optional JWT secret
+	jwtSecret              []byte   // optional JWT secret
+	auth                   *rpcAuth // SUAVE SPECIFIC: optional client credentials and namespace policies
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
@@ -312,9 +313,13 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	// --- SUAVE SPECIFIC ---
+	if err := srv.SetPolicies(config.auth.serverPolicies(false)); err != nil {
+		return err
+	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: newHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret, config.auth),
 		server:  srv,
 	})
 	return nil
@@ -347,9 +352,13 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	// --- SUAVE SPECIFIC ---
+	if err := srv.SetPolicies(config.auth.serverPolicies(false)); err != nil {
+		return err
+	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: newWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret, config.auth),
 		server:  srv,
 	})
 	return nil
@@ -395,21 +404,24 @@ func isWebsocket(r *http.Request) bool {
 
 // NewHTTPHandlerStack returns wrapped http-related handlers
 func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte) http.Handler {
+	return newHTTPHandlerStack(srv, cors, vhosts, jwtSecret, nil)
+}
+
+func newHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte, auth *rpcAuth) http.Handler {
 	// Wrap the CORS-handler within a host-handler
 	handler := newCorsHandler(srv, cors)
 	handler = newVHostHandler(vhosts, handler)
-	if len(jwtSecret) != 0 {
-		handler = newJWTHandler(jwtSecret, handler)
-	}
+	handler = newAuthHandler(jwtSecret, auth, handler)
 	return newGzipHandler(handler)
 }
 
 // NewWSHandlerStack returns a wrapped ws-related handler.
 func NewWSHandlerStack(srv http.Handler, jwtSecret []byte) http.Handler {
-	if len(jwtSecret) != 0 {
-		return newJWTHandler(jwtSecret, srv)
-	}
-	return srv
+	return newWSHandlerStack(srv, jwtSecret, nil)
+}
+
+func newWSHandlerStack(srv http.Handler, jwtSecret []byte, auth *rpcAuth) http.Handler {
+	return newAuthHandler(jwtSecret, auth, srv)
 }
 
 func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
@@ -598,14 +610,14 @@ func newIPCServer(log log.Logger, endpoint string) *ipcServer {
 }
 
 // start starts the httpServer's http.Server
-func (is *ipcServer) start(apis []rpc.API) error {
+func (is *ipcServer) start(apis []rpc.API, auth *rpcAuth) error {
 	is.mu.Lock()
 	defer is.mu.Unlock()
 
 	if is.listener != nil {
 		return nil // already running
 	}
-	listener, srv, err := rpc.StartIPCEndpoint(is.endpoint, apis)
+	listener, srv, err := rpc.StartIPCEndpointWithPolicies(is.endpoint, apis, auth.serverPolicies(true)) // SUAVE SPECIFIC
 	if err != nil {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
 		return err
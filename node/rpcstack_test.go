@@ -18,6 +18,8 @@ package node
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -449,6 +451,71 @@ func TestJWT(t *testing.T) {
 	srv.stop()
 }
 
+func TestRPCPolicies(t *testing.T) {
+	var secret = []byte("secret")
+	auth := &rpcAuth{
+		jwtSecret: secret,
+		apiKeys:   map[string]string{"alice": "key-a", "bob": "key-b"},
+		policies:  []RPCPolicy{{Namespaces: []string{"test"}, Protected: true, Clients: []string{"bob"}}},
+	}
+	cfg := rpcEndpointConfig{auth: auth}
+	httpcfg := &httpConfig{rpcEndpointConfig: cfg}
+	wscfg := &wsConfig{Origins: []string{"*"}, rpcEndpointConfig: cfg}
+	srv := createAndStartServer(t, httpcfg, true, wscfg, nil)
+	defer srv.stop()
+	wsUrl := fmt.Sprintf("ws://%v", srv.listenAddr())
+	htUrl := fmt.Sprintf("http://%v", srv.listenAddr())
+
+	type result struct {
+		Result interface{}
+		Error  *struct{ Code int }
+	}
+	decode := func(resp *http.Response) result {
+		t.Helper()
+		var res result
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// the client is identified by the name of its key or by its token subject
+	resp := rpcRequest(t, htUrl, "test_subject", "X-API-Key", "key-b")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "bob", decode(resp).Result)
+
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"iat": time.Now().Unix(), "sub": "carol"}).SignedString(secret)
+	resp = rpcRequest(t, htUrl, "test_subject", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "carol", decode(resp).Result)
+	assert.NoError(t, wsRequest(t, wsUrl, "X-API-Key", "key-a"))
+
+	// the protected namespace is denied to the clients without credentials,
+	// and to the ones not granted access
+	resp = rpcRequest(t, htUrl, "test_subject")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if res := decode(resp); assert.NotNil(t, res.Error) {
+		assert.Equal(t, -32010, res.Error.Code)
+	}
+	resp = rpcRequest(t, htUrl, "test_subject", "X-API-Key", "key-a")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if res := decode(resp); assert.NotNil(t, res.Error) {
+		assert.Equal(t, -32010, res.Error.Code)
+	}
+	// while the other namespaces are public
+	resp = rpcRequest(t, htUrl, "rpc_modules")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, decode(resp).Error)
+
+	// invalid credentials are rejected
+	resp = rpcRequest(t, htUrl, "test_subject", "X-API-Key", "key-c")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"iat": time.Now().Unix(), "sub": "carol"}).SignedString([]byte("other"))
+	resp = rpcRequest(t, htUrl, "test_subject", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Error(t, wsRequest(t, wsUrl, "X-API-Key", "key-c"))
+}
+
 func TestGzipHandler(t *testing.T) {
 	type gzipTest struct {
 		name    string
@@ -611,6 +678,10 @@ func (s *testService) Greet() string {
 	return "Hello"
 }
 
+func (s *testService) Subject(ctx context.Context) string {
+	return rpc.PeerInfoFromContext(ctx).Subject
+}
+
 func (s *testService) Sleep() {
 	time.Sleep(1500 * time.Millisecond)
 }
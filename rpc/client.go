@@ -88,6 +88,7 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	policies             policySet // SUAVE SPECIFIC
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -118,8 +119,13 @@ type clientConn struct {
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
-	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
+	// --- SUAVE SPECIFIC ---
+	info := conn.peerInfo()
+	info.connID = nextConnID.Add(1)
+	ctx = context.WithValue(ctx, peerInfoContextKey{}, info)
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	// --- SUAVE SPECIFIC ---
+	handler.policies = c.policies
 	return &clientConn{conn, handler}
 }
 
@@ -247,6 +253,7 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		policies:             cfg.policies,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
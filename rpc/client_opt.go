@@ -41,6 +41,9 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+
+	// --- SUAVE SPECIFIC ---
+	policies policySet
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -25,12 +25,23 @@ import (
 
 // StartIPCEndpoint starts an IPC endpoint.
 func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, error) {
+	return StartIPCEndpointWithPolicies(ipcEndpoint, apis, nil)
+}
+
+// --- SUAVE SPECIFIC ---
+
+// StartIPCEndpointWithPolicies starts an IPC endpoint enforcing the given
+// policies, see Server.SetPolicies.
+func StartIPCEndpointWithPolicies(ipcEndpoint string, apis []API, policies []*Policy) (net.Listener, *Server, error) {
 	// Register all the APIs exposed by the services.
 	var (
 		handler    = NewServer()
 		regMap     = make(map[string]struct{})
 		registered []string
 	)
+	if err := handler.SetPolicies(policies); err != nil {
+		return nil, nil, err
+	}
 	for _, api := range apis {
 		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 			log.Info("IPC registration failed", "namespace", api.Namespace, "error", err)
@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -63,6 +64,9 @@ type handler struct {
 	batchRequestLimit    int
 	batchResponseMaxSize int
 
+	// --- SUAVE SPECIFIC ---
+	policies policySet
+
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
 }
@@ -491,6 +495,15 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	// --- SUAVE SPECIFIC ---
+	if !msg.isUnsubscribe() {
+		release, err := h.policies.acquire(cp.ctx, msg.Method)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
+
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -509,7 +522,14 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	// --- SUAVE SPECIFIC ---
+	ctx, span := telemetry.StartServer(cp.ctx, msg.Method)
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error != nil {
+		telemetry.End(span, answer.Error)
+	} else {
+		span.End()
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -30,6 +30,8 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 )
 
 const (
@@ -218,6 +220,8 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	req.Header = hc.headers.Clone()
 	hc.mu.Unlock()
 	setHeaders(req.Header, headersFromContext(ctx))
+	// --- SUAVE SPECIFIC ---
+	telemetry.Inject(ctx, req.Header)
 
 	if hc.auth != nil {
 		if err := hc.auth(req.Header); err != nil {
@@ -323,8 +327,15 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	// --- SUAVE SPECIFIC ---
+	connInfo.Subject = authSubjectFromContext(r.Context())
+	connInfo.AuthNamespaces = authNamespacesFromContext(r.Context())
+
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
+	// --- SUAVE SPECIFIC ---
+	// The calls of the request are traced as children of the span of the caller.
+	ctx = telemetry.Extract(ctx, r.Header)
 
 	// All checks passed, create a codec that reads directly from the request body
 	// until EOF, writes the response to w, and orders the server to process a
@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	errcodeUnauthorized  = -32010
+	errcodeLimitExceeded = -32005 // EIP-1474
+)
+
+// maxPolicyClients is the number of clients of a policy above which the idle
+// ones are forgotten.
+const maxPolicyClients = 10_000
+
+// Policy is the access policy of a set of namespaces of the server. The limits
+// apply to every client separately: the authenticated clients are told apart by
+// their subject, the other ones by their address, or by their connection if
+// they have none.
+type Policy struct {
+	Namespaces []string
+
+	// Protected restricts the namespaces to the authenticated clients whose
+	// credential grants access to them, see WithAuthNamespaces.
+	Protected bool
+
+	// RequestsPerSecond limits the rate of the calls of a client, with bursts of
+	// up to RequestBurst calls. No limit if zero.
+	RequestsPerSecond float64
+	RequestBurst      int
+
+	// MaxConcurrentRequests limits the calls of a client in progress at once.
+	// No limit if zero.
+	MaxConcurrentRequests int
+}
+
+type policyError struct {
+	code    int
+	message string
+}
+
+func (e *policyError) Error() string  { return e.message }
+func (e *policyError) ErrorCode() int { return e.code }
+
+// policyState is a policy and the usage of its clients.
+type policyState struct {
+	*Policy
+	mu      sync.Mutex
+	clients map[string]*policyClient
+}
+
+type policyClient struct {
+	limiter *rate.Limiter // nil without rate limit
+	active  int
+}
+
+// policySet is the set of policies of a server by namespace.
+type policySet map[string]*policyState
+
+// newPolicySet checks the policies and indexes them by namespace.
+func newPolicySet(policies []*Policy) (policySet, error) {
+	set := make(policySet)
+	for _, p := range policies {
+		if p.RequestsPerSecond < 0 || p.RequestBurst < 0 || p.MaxConcurrentRequests < 0 {
+			return nil, fmt.Errorf("negative limits in the policy of %v", p.Namespaces)
+		}
+		state := &policyState{Policy: p, clients: make(map[string]*policyClient)}
+		for _, ns := range p.Namespaces {
+			if _, ok := set[ns]; ok {
+				return nil, fmt.Errorf("namespace %q in more than one policy", ns)
+			}
+			set[ns] = state
+		}
+	}
+	return set, nil
+}
+
+// acquire checks that the client of the request can call the method, and
+// counts the call until release is called.
+func (set policySet) acquire(ctx context.Context, method string) (release func(), err error) {
+	namespace, _, _ := strings.Cut(method, serviceMethodSeparator)
+	p, ok := set[namespace]
+	if !ok {
+		return func() {}, nil
+	}
+	info := PeerInfoFromContext(ctx)
+	if p.Protected && !slices.Contains(info.AuthNamespaces, namespace) {
+		return nil, &policyError{errcodeUnauthorized, fmt.Sprintf("unauthorized access to the %s namespace", namespace)}
+	}
+	return p.acquire(policyClientKey(info))
+}
+
+func (p *policyState) acquire(key string) (func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, ok := p.clients[key]
+	if !ok {
+		if len(p.clients) >= maxPolicyClients {
+			p.prune()
+		}
+		client = &policyClient{}
+		if p.RequestsPerSecond > 0 {
+			client.limiter = rate.NewLimiter(rate.Limit(p.RequestsPerSecond), max(p.RequestBurst, 1))
+		}
+		p.clients[key] = client
+	}
+	if p.MaxConcurrentRequests > 0 && client.active >= p.MaxConcurrentRequests {
+		return nil, &policyError{errcodeLimitExceeded, "too many concurrent requests"}
+	}
+	if client.limiter != nil && !client.limiter.Allow() {
+		return nil, &policyError{errcodeLimitExceeded, "request rate limit exceeded"}
+	}
+	client.active++
+	return func() {
+		p.mu.Lock()
+		client.active--
+		p.mu.Unlock()
+	}, nil
+}
+
+// prune forgets the clients without call in progress whose rate limit is back
+// to a full burst, which are the same as new clients. The lock must be held.
+func (p *policyState) prune() {
+	for key, client := range p.clients {
+		if client.active == 0 && (client.limiter == nil || client.limiter.Tokens() >= float64(client.limiter.Burst())) {
+			delete(p.clients, key)
+		}
+	}
+}
+
+// nextConnID is the identifier of the next connection, see PeerInfo.
+var nextConnID atomic.Uint64
+
+// policyClientKey identifies the client of a call for the limits. The clients
+// without address, like the ones of the IPC endpoint, are told apart by their
+// connection.
+func policyClientKey(info PeerInfo) string {
+	if info.Subject != "" {
+		return "subject:" + info.Subject
+	}
+	host, _, err := net.SplitHostPort(info.RemoteAddr)
+	if err != nil {
+		host = info.RemoteAddr
+	}
+	if host == "" {
+		return fmt.Sprintf("conn:%d", info.connID)
+	}
+	return "addr:" + host
+}
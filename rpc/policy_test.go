@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func policyErrorCode(err error) int {
+	var rerr Error
+	if !errors.As(err, &rerr) {
+		return 0
+	}
+	return rerr.ErrorCode()
+}
+
+func TestPolicyProtected(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.SetPolicies([]*Policy{{Namespaces: []string{"test"}, Protected: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the credential of the client is read by the HTTP handler
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Header.Get("X-Test-Auth") != "" {
+			ctx = WithAuthSubject(ctx, "alice")
+			ctx = WithAuthNamespaces(ctx, []string{r.Header.Get("X-Test-Auth")})
+		}
+		server.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	defer httpsrv.Close()
+
+	tests := []struct {
+		auth string
+		code int
+	}{
+		{auth: "", code: errcodeUnauthorized},
+		{auth: "nftest", code: errcodeUnauthorized},
+		{auth: "test", code: 0},
+	}
+	for _, tt := range tests {
+		client, err := DialHTTP(httpsrv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SetHeader("X-Test-Auth", tt.auth)
+		var info PeerInfo
+		err = client.Call(&info, "test_peerInfo")
+		if code := policyErrorCode(err); code != tt.code {
+			t.Errorf("auth %q: have code %d (%v), want %d", tt.auth, code, err, tt.code)
+		}
+		if err == nil && info.Subject != "alice" {
+			t.Errorf("auth %q: have subject %q", tt.auth, info.Subject)
+		}
+		client.Close()
+	}
+
+	// the namespaces without policy are open
+	client := DialInProc(server)
+	defer client.Close()
+	if err := client.Call(nil, "rpc_modules"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_null"); policyErrorCode(err) != errcodeUnauthorized {
+		t.Fatalf("in-process call: have %v, want unauthorized", err)
+	}
+}
+
+func TestPolicyRateLimit(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.SetPolicies([]*Policy{{Namespaces: []string{"test"}, RequestsPerSecond: 0.01, RequestBurst: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := client.Call(nil, "test_null"); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if err := client.Call(nil, "test_null"); policyErrorCode(err) != errcodeLimitExceeded {
+		t.Fatalf("have %v, want rate limit exceeded", err)
+	}
+
+	// the connections without address have their own limits
+	other := DialInProc(server)
+	defer other.Close()
+	if err := other.Call(nil, "test_null"); err != nil {
+		t.Fatalf("other connection: %v", err)
+	}
+}
+
+func TestPolicyConcurrencyLimit(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.SetPolicies([]*Policy{{Namespaces: []string{"test"}, MaxConcurrentRequests: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- client.Call(nil, "test_sleep", 200*time.Millisecond)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := client.Call(nil, "test_null"); policyErrorCode(err) != errcodeLimitExceeded {
+		t.Fatalf("have %v, want concurrency limit exceeded", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPolicyOverlap(t *testing.T) {
+	server := NewServer()
+	err := server.SetPolicies([]*Policy{
+		{Namespaces: []string{"test"}},
+		{Namespaces: []string{"test"}},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a namespace in two policies")
+	}
+}
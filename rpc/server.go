@@ -52,6 +52,9 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+
+	// --- SUAVE SPECIFIC ---
+	policies policySet
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -80,6 +83,22 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// --- SUAVE SPECIFIC ---
+
+// SetPolicies sets the access policies of the namespaces of the server. The
+// namespaces without policy are open to every client without limit.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetPolicies(policies []*Policy) error {
+	set, err := newPolicySet(policies)
+	if err != nil {
+		return err
+	}
+	s.policies = set
+	return nil
+}
+
 // SetHTTPBodyLimit sets the size limit for HTTP requests.
 //
 // This method should be called before processing any requests via ServeHTTP.
@@ -112,6 +131,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		policies:           s.policies,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -147,6 +167,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	// --- SUAVE SPECIFIC ---
+	h.policies = s.policies
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -219,6 +241,18 @@ type PeerInfo struct {
 		Origin    string
 		Host      string
 	}
+
+	// --- SUAVE SPECIFIC ---
+	// Subject identifies the authenticated client of an HTTP or WebSocket
+	// connection: the subject of its JWT or the name of its API key. It is
+	// empty if the client is not authenticated.
+	Subject string
+
+	// AuthNamespaces are the protected namespaces the credential of the client
+	// grants access to, see Policy.
+	AuthNamespaces []string
+
+	connID uint64 // identifies the connection of the clients without address
 }
 
 type peerInfoContextKey struct{}
@@ -231,3 +265,33 @@ func PeerInfoFromContext(ctx context.Context) PeerInfo {
 	info, _ := ctx.Value(peerInfoContextKey{}).(PeerInfo)
 	return info
 }
+
+// --- SUAVE SPECIFIC ---
+
+type authSubjectContextKey struct{}
+
+// WithAuthSubject returns a copy of the context of an HTTP request carrying the
+// identity of the authenticated client. It is reported as the Subject of the
+// PeerInfo of the calls made with the request or its WebSocket connection.
+func WithAuthSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, authSubjectContextKey{}, subject)
+}
+
+func authSubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(authSubjectContextKey{}).(string)
+	return subject
+}
+
+type authNamespacesContextKey struct{}
+
+// WithAuthNamespaces returns a copy of the context of an HTTP request carrying
+// the protected namespaces the credential of the client grants access to. They
+// are reported as the AuthNamespaces of the PeerInfo like WithAuthSubject.
+func WithAuthNamespaces(ctx context.Context, namespaces []string) context.Context {
+	return context.WithValue(ctx, authNamespacesContextKey{}, namespaces)
+}
+
+func authNamespacesFromContext(ctx context.Context) []string {
+	namespaces, _ := ctx.Value(authNamespacesContextKey{}).([]string)
+	return namespaces
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := telemetry.NewWithProcessor(telemetry.Config{Namespaces: []string{"test"}}, recorder)
+	telemetry.SetTracer(tracer)
+	defer telemetry.SetTracer(nil)
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// the client sends the span of its context in the traceparent header
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	if err := client.CallContext(ctx, nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	// the calls of the other namespaces are not traced
+	if err := client.CallContext(ctx, nil, "nftest_echo", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("have %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "test_echo" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("have span %q of kind %s", span.Name(), span.SpanKind())
+	}
+	if span.SpanContext().TraceID() != traceID || span.Parent().SpanID() != spanID {
+		t.Errorf("span is not a child of the caller: trace %s, parent %s", span.SpanContext().TraceID(), span.Parent().SpanID())
+	}
+}
@@ -61,6 +61,9 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
+		// --- SUAVE SPECIFIC ---
+		codec.(*websocketCodec).info.Subject = authSubjectFromContext(r.Context())
+		codec.(*websocketCodec).info.AuthNamespaces = authNamespacesFromContext(r.Context())
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -2,7 +2,6 @@ package backends
 
 import (
 	"context"
-	"math/big"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
@@ -22,8 +21,8 @@ var _ EthBackend = &EthBackendServer{}
 // to resolve the EthBackend server queries
 type EthBackendServerBackend interface {
 	CurrentHeader() *types.Header
-	BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, []*types.BlobTxSidecar, error)
-	BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, []*types.BlobTxSidecar, error)
+	BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error)
+	BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error)
 	Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error)
 }
 
@@ -55,7 +54,7 @@ func (e *EthBackendServer) BuildEthBlock(ctx context.Context, buildArgs *types.B
 		return nil, err
 	}
 
-	return engine.BlockToExecutableData(block, profit, scs), nil
+	return executionPayloadEnvelope(block, profit, scs), nil
 }
 
 func (e *EthBackendServer) BuildEthBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
@@ -78,9 +77,17 @@ func (e *EthBackendServer) BuildEthBlockFromBundles(ctx context.Context, buildAr
 		return nil, err
 	}
 
-	return engine.BlockToExecutableData(block, profit, scs), nil
+	return executionPayloadEnvelope(block, profit, scs), nil
 }
 
 func (e *EthBackendServer) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
 	return e.b.Call(ctx, contractAddr, input)
 }
+
+// executionPayloadEnvelope creates the response of a block building request,
+// whose value is the total profit of the block for its fee recipient.
+func executionPayloadEnvelope(block *types.Block, profit *types.BlockProfit, sidecars []*types.BlobTxSidecar) *engine.ExecutionPayloadEnvelope {
+	envelope := engine.BlockToExecutableData(block, profit.Total(), sidecars)
+	envelope.Profit = profit
+	return envelope
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/stretchr/testify/require"
@@ -22,8 +23,10 @@ func TestEthBackend_Compatibility(t *testing.T) {
 
 	clt := &RemoteEthBackend{client: rpc.DialInProc(srv)}
 
-	_, err := clt.BuildEthBlock(context.Background(), &types.BuildBlockArgs{}, nil)
+	envelope, err := clt.BuildEthBlock(context.Background(), &types.BuildBlockArgs{}, nil)
 	require.NoError(t, err)
+	require.Equal(t, big.NewInt(11000), envelope.BlockValue)
+	require.Equal(t, big.NewInt(11000), envelope.Profit.PriorityFees.ToInt())
 
 	_, err = clt.BuildEthBlockFromBundles(context.Background(), &types.BuildBlockArgs{}, nil)
 	require.NoError(t, err)
@@ -36,18 +39,18 @@ func (n *mockBackend) CurrentHeader() *types.Header {
 	return &types.Header{}
 }
 
-func (n *mockBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, []*types.BlobTxSidecar, error) {
+func (n *mockBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error) {
 	block := types.NewBlock(&types.Header{GasUsed: 1000, BaseFee: big.NewInt(1)}, txs, nil, nil, trie.NewStackTrie(nil))
-	return block, big.NewInt(11000), nil, nil
+	return block, &types.BlockProfit{PriorityFees: (*hexutil.Big)(big.NewInt(11000))}, nil, nil
 }
 
-func (n *mockBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, []*types.BlobTxSidecar, error) {
+func (n *mockBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *types.BlockProfit, []*types.BlobTxSidecar, error) {
 	var txs types.Transactions
 	for _, bundle := range bundles {
 		txs = append(txs, bundle.Txs...)
 	}
 	block := types.NewBlock(&types.Header{GasUsed: 1000, BaseFee: big.NewInt(1)}, txs, nil, nil, trie.NewStackTrie(nil))
-	return block, big.NewInt(11000), nil, nil
+	return block, &types.BlockProfit{PriorityFees: (*hexutil.Big)(big.NewInt(11000))}, nil, nil
 }
 
 func (n *mockBackend) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
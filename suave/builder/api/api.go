@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"math/big"
 
 	denebBuilder "github.com/attestantio/go-builder-client/api/deneb"
@@ -9,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 )
 
@@ -18,11 +21,21 @@ import (
 //go:generate go run github.com/fjl/gencodec -type SimulatedLog -field-override simulateLogMarshaling -out gen_simulateLog_json.go
 
 type Bundle struct {
-	BlockNumber     *big.Int           `json:"blockNumber,omitempty"` // if BlockNumber is set it must match DecryptionCondition!
-	MaxBlock        *big.Int           `json:"maxBlock,omitempty"`
-	Txs             types.Transactions `json:"txs"`
-	RevertingHashes []common.Hash      `json:"revertingHashes,omitempty"`
-	RefundPercent   *int               `json:"percent,omitempty"`
+	BlockNumber     *big.Int                 `json:"blockNumber,omitempty"` // if BlockNumber is set it must match DecryptionCondition!
+	MaxBlock        *big.Int                 `json:"maxBlock,omitempty"`
+	Txs             types.Transactions       `json:"txs"`
+	RevertingHashes []common.Hash            `json:"revertingHashes,omitempty"`
+	RefundPercent   *int                     `json:"percent,omitempty"`
+	Constraints     *types.BundleConstraints `json:"constraints,omitempty"`
+}
+
+// Hash returns the identifier of the bundle, the hash of its transaction hashes
+func (bundle *Bundle) Hash() common.Hash {
+	hashes := make([][]byte, len(bundle.Txs))
+	for i, tx := range bundle.Txs {
+		hashes[i] = tx.Hash().Bytes()
+	}
+	return crypto.Keccak256Hash(hashes...)
 }
 
 func (bundle *Bundle) RevertingHashesMap() map[common.Hash]struct{} {
@@ -42,7 +55,10 @@ type BuildBlockArgs struct {
 	GasLimit       uint64              `json:"gasLimit"`
 	Random         common.Hash         `json:"random"`
 	Withdrawals    []*types.Withdrawal `json:"withdrawals"`
+	BeaconRoot     *common.Hash        `json:"beaconRoot"`
 	Extra          []byte              `json:"extra"`
+	ParentSession  string              `json:"parentSession,omitempty"` // builds on the last block of the session instead of Parent
+	Record         bool                `json:"record,omitempty"`        // records the calls of the session for ExportSession
 }
 
 // field type overrides for gencodec
@@ -55,17 +71,38 @@ type buildBlockArgsMarshaling struct {
 }
 
 type SimulateTransactionResult struct {
-	Egp     uint64          `json:"egp"`
-	Logs    []*SimulatedLog `json:"logs"`
-	Success bool            `json:"success"`
-	Error   string          `json:"error"`
+	Egp       uint64          `json:"egp"`
+	Logs      []*SimulatedLog `json:"logs"`
+	Success   bool            `json:"success"`
+	Error     string          `json:"error"`
+	ErrorCode int             `json:"errorCode,omitempty"` // code of the error, see ErrorCode
+	Reads     *StateAccess    `json:"reads,omitempty"`
+	Writes    *StateAccess    `json:"writes,omitempty"`
 }
 
 type SimulateBundleResult struct {
+	Hash                       common.Hash                  `json:"hash"`
 	Egp                        uint64                       `json:"egp"`
 	SimulateTransactionResults []*SimulateTransactionResult `json:"simulateTransactionResults"`
 	Success                    bool                         `json:"success"`
 	Error                      string                       `json:"error"`
+	ErrorCode                  int                          `json:"errorCode,omitempty"`        // code of the error, see ErrorCode
+	FailedConstraint           string                       `json:"failedConstraint,omitempty"` // constraint of the bundle that was not met
+	CoinbaseProfit             *big.Int                     `json:"coinbaseProfit,omitempty"`
+	Reads                      *StateAccess                 `json:"reads,omitempty"`
+	Writes                     *StateAccess                 `json:"writes,omitempty"`
+}
+
+// StateAccess is the set of accounts and storage slots accessed during a simulation
+type StateAccess struct {
+	Accounts []common.Address                 `json:"accounts"`
+	Storage  map[common.Address][]common.Hash `json:"storage"`
+}
+
+// BundleConflict reports whether two simulated bundles access overlapping state
+type BundleConflict struct {
+	Conflict bool         `json:"conflict"`
+	State    *StateAccess `json:"state,omitempty"` // state written by one bundle and accessed by the other
 }
 
 // field type overrides for gencodec
@@ -83,6 +120,164 @@ type simulateLogMarshaling struct {
 	Data hexutil.Bytes
 }
 
+const (
+	// MergeStrategyGreedyEgp applies the bundles by decreasing effective gas price
+	MergeStrategyGreedyEgp = "greedy-egp"
+	// MergeStrategyGreedyProfit applies the bundles by decreasing coinbase profit
+	MergeStrategyGreedyProfit = "greedy-profit"
+	// MergeStrategyGreedyResimulate applies the bundles by decreasing effective gas
+	// price and simulates the remaining ones again whenever a bundle is dropped
+	MergeStrategyGreedyResimulate = "greedy-resimulate"
+)
+
+type MergeStrategy struct {
+	Name    string `json:"name"`
+	Timeout uint64 `json:"timeout"` // deadline in milliseconds, zero means no deadline
+}
+
+type MergeBundlesResult struct {
+	Landed  []*SimulateBundleResult `json:"landed"`
+	Dropped []*DroppedBundle        `json:"dropped"`
+	Profit  *big.Int                `json:"profit"`
+}
+
+type DroppedBundle struct {
+	Hash   common.Hash `json:"hash"`
+	Reason string      `json:"reason"`
+}
+
+const (
+	BundleStatusPending  = "pending"
+	BundleStatusIncluded = "included"
+	BundleStatusExpired  = "expired"
+	BundleStatusInvalid  = "invalid" // only part of the bundle was included on-chain
+)
+
+// BundleStatus is the status of a bundle sent to the bundle store
+type BundleStatus struct {
+	Hash        common.Hash `json:"hash"`
+	Status      string      `json:"status"`
+	MinBlock    *big.Int    `json:"minBlock,omitempty"`
+	MaxBlock    *big.Int    `json:"maxBlock,omitempty"`
+	BlockNumber *big.Int    `json:"blockNumber,omitempty"` // block that included the bundle transactions
+}
+
+// FillPendingOpts are the options to fill a session with the pending transactions
+// of the pool. Every field is optional.
+type FillPendingOpts struct {
+	Timeout            uint64           `json:"timeout,omitempty"`     // deadline in milliseconds, the transactions added until then are kept
+	ReservedGas        uint64           `json:"reservedGas,omitempty"` // gas left available for later bundles or payments
+	MinTip             *big.Int         `json:"minTip,omitempty"`      // defaults to the miner gas price
+	ExcludedSenders    []common.Address `json:"excludedSenders,omitempty"`
+	ExcludedRecipients []common.Address `json:"excludedRecipients,omitempty"`
+	MaxTxs             uint64           `json:"maxTxs,omitempty"` // maximum number of pool transactions to add
+	IncludeBlobTxs     bool             `json:"includeBlobTxs,omitempty"`
+}
+
+// FillPendingResult lists the pool transactions added to the session
+type FillPendingResult struct {
+	Txs              []common.Hash `json:"txs"`
+	GasUsed          uint64        `json:"gasUsed"`
+	DeadlineExceeded bool          `json:"deadlineExceeded"`
+}
+
+// NewSessionFromBlockOpts are the options to open a session on a chain block
+type NewSessionFromBlockOpts struct {
+	TxIndex uint64 `json:"txIndex"`          // number of transactions of the block re-applied in the session
+	Record  bool   `json:"record,omitempty"` // records the calls of the session for ExportSession
+}
+
+// TxsOrBundle is the item inserted in a session, either a list of transactions
+// applied one by one or a bundle. It is encoded as a JSON array of transactions
+// or as a bundle object.
+type TxsOrBundle struct {
+	Txs    types.Transactions
+	Bundle *Bundle
+}
+
+func (t TxsOrBundle) MarshalJSON() ([]byte, error) {
+	if t.Bundle != nil {
+		return json.Marshal(t.Bundle)
+	}
+	return json.Marshal(t.Txs)
+}
+
+func (t *TxsOrBundle) UnmarshalJSON(input []byte) error {
+	input = bytes.TrimSpace(input)
+	if len(input) > 0 && input[0] == '[' {
+		return json.Unmarshal(input, &t.Txs)
+	}
+	return json.Unmarshal(input, &t.Bundle)
+}
+
+// SessionItemResult is the result of a transaction or a bundle of a session, in
+// the order of the session
+type SessionItemResult struct {
+	Hash   common.Hash                `json:"hash"` // hash of the transaction or of the bundle
+	Tx     *SimulateTransactionResult `json:"tx,omitempty"`
+	Bundle *SimulateBundleResult      `json:"bundle,omitempty"`
+}
+
+// SessionDump is the recording of a session, it can be replayed into a new
+// session with ImportSession
+type SessionDump struct {
+	Args       *BuildBlockArgs `json:"args"`
+	ParentHash common.Hash     `json:"parentHash"`
+	Calls      []*SessionCall  `json:"calls"`
+}
+
+// SessionCall is a call made to a session, in the order it was made
+type SessionCall struct {
+	Method string            `json:"method"`
+	Inputs []json.RawMessage `json:"inputs"`
+	Result json.RawMessage   `json:"result"`
+	Error  string            `json:"error,omitempty"`
+
+	// pool transactions added by fillPending, replayed in place of the pool
+	Txs types.Transactions `json:"txs,omitempty"`
+}
+
+// ImportSessionResult is the outcome of the replay of a session dump
+type ImportSessionResult struct {
+	SessionId  string             `json:"sessionId"`
+	Replayed   int                `json:"replayed"` // number of calls replayed
+	Divergence *SessionDivergence `json:"divergence,omitempty"`
+}
+
+// SessionDivergence is the first call whose outcome differs from the recording,
+// the replay stops there
+type SessionDivergence struct {
+	Index          int             `json:"index"`
+	Method         string          `json:"method"`
+	ExpectedResult json.RawMessage `json:"expectedResult"`
+	ActualResult   json.RawMessage `json:"actualResult"`
+	ExpectedError  string          `json:"expectedError,omitempty"`
+	ActualError    string          `json:"actualError,omitempty"`
+}
+
+// Budget is an amount of each resource spent by the sessions on the simulation
+// of transactions. In a budget limit, zero means no limit.
+type Budget struct {
+	Gas         uint64 `json:"gas"`
+	Txs         uint64 `json:"txs"`
+	Bundles     uint64 `json:"bundles"`
+	CPUTime     uint64 `json:"cpuTime"` // milliseconds spent applying transactions
+	StateCopies uint64 `json:"stateCopies"`
+}
+
+// SessionInfo describes a session and the resources spent by it and by the
+// other sessions of its client
+type SessionInfo struct {
+	SessionId    string      `json:"sessionId"`
+	ParentHash   common.Hash `json:"parentHash"`
+	BlockNumber  *big.Int    `json:"blockNumber"`
+	Txs          uint64      `json:"txs"` // number of transactions in the session block
+	Usage        *Budget     `json:"usage"`
+	Budget       *Budget     `json:"budget"`
+	ClientUsage  *Budget     `json:"clientUsage"` // resources spent by the sessions of the client over the budget window
+	ClientBudget *Budget     `json:"clientBudget"`
+}
+
 // SubmitBlockRequest is an extension of the builder.SubmitBlockRequest with the root
 // of the bid that needs to be signed
 type SubmitBlockRequest struct {
@@ -92,9 +287,26 @@ type SubmitBlockRequest struct {
 
 type API interface {
 	NewSession(ctx context.Context, args *BuildBlockArgs) (string, error)
+	NewSessionFromBlock(ctx context.Context, blockHash common.Hash, opts *NewSessionFromBlockOpts) (string, error)
 	AddTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error)
 	AddTransactions(ctx context.Context, sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error)
 	AddBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error)
+	InsertAt(ctx context.Context, sessionId string, index uint64, item TxsOrBundle) ([]*SessionItemResult, error)
+	Remove(ctx context.Context, sessionId string, index uint64) ([]*SessionItemResult, error)
+	SimulateTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error)
+	SimulateBundle(ctx context.Context, sessionId string, bundle *Bundle) (*SimulateBundleResult, error)
+	SimulateBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error)
+	CheckBundleConflict(ctx context.Context, sessionId string, bundleA, bundleB common.Hash) (*BundleConflict, error)
+	MergeBundles(ctx context.Context, sessionId string, bundles []*Bundle, strategy *MergeStrategy) (*MergeBundlesResult, error)
+	MergeStoredBundles(ctx context.Context, sessionId string, strategy *MergeStrategy) (*MergeBundlesResult, error)
+	SendBundle(ctx context.Context, bundle *Bundle) (common.Hash, error)
+	GetBundleStatus(ctx context.Context, hash common.Hash) (*BundleStatus, error)
+	SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlock *big.Int) (common.Hash, error)
+	CancelPrivateTransaction(ctx context.Context, hash common.Hash) error
+	FillPending(ctx context.Context, sessionId string, opts *FillPendingOpts) (*FillPendingResult, error)
+	ExportSession(ctx context.Context, sessionId string) (*SessionDump, error)
+	ImportSession(ctx context.Context, dump *SessionDump) (*ImportSessionResult, error)
+	GetSessionInfo(ctx context.Context, sessionId string) (*SessionInfo, error)
 	BuildBlock(ctx context.Context, sessionId string) error
 	Bid(ctx context.Context, sessioId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error)
 	GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error)
@@ -34,48 +34,153 @@ func NewClientFromRPC(rpc rpcClient) *APIClient {
 	return &APIClient{rpc: rpc}
 }
 
+// call calls the method and converts the suavex errors back to Error values.
+func (a *APIClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return fromRPCError(a.rpc.CallContext(ctx, result, method, args...))
+}
+
 func (a *APIClient) NewSession(ctx context.Context, args *BuildBlockArgs) (string, error) {
 	var id string
-	err := a.rpc.CallContext(ctx, &id, "suavex_newSession", args)
+	err := a.call(ctx, &id, "suavex_newSession", args)
+	return id, err
+}
+
+func (a *APIClient) NewSessionFromBlock(ctx context.Context, blockHash common.Hash, opts *NewSessionFromBlockOpts) (string, error) {
+	var id string
+	err := a.call(ctx, &id, "suavex_newSessionFromBlock", blockHash, opts)
 	return id, err
 }
 
 func (a *APIClient) AddTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
 	var receipt *SimulateTransactionResult
-	err := a.rpc.CallContext(ctx, &receipt, "suavex_addTransaction", sessionId, tx)
+	err := a.call(ctx, &receipt, "suavex_addTransaction", sessionId, tx)
 	return receipt, err
 }
 
 func (a *APIClient) AddTransactions(ctx context.Context, sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error) {
 	var receipt []*SimulateTransactionResult
-	err := a.rpc.CallContext(ctx, &receipt, "suavex_addTransactions", sessionId, txs)
+	err := a.call(ctx, &receipt, "suavex_addTransactions", sessionId, txs)
 	return receipt, err
 }
 
 func (a *APIClient) AddBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
 	var receipt []*SimulateBundleResult
-	err := a.rpc.CallContext(ctx, &receipt, "suavex_addBundles", sessionId, bundles)
+	err := a.call(ctx, &receipt, "suavex_addBundles", sessionId, bundles)
 	return receipt, err
 }
 
+func (a *APIClient) InsertAt(ctx context.Context, sessionId string, index uint64, item TxsOrBundle) ([]*SessionItemResult, error) {
+	var results []*SessionItemResult
+	err := a.call(ctx, &results, "suavex_insertAt", sessionId, index, item)
+	return results, err
+}
+
+func (a *APIClient) Remove(ctx context.Context, sessionId string, index uint64) ([]*SessionItemResult, error) {
+	var results []*SessionItemResult
+	err := a.call(ctx, &results, "suavex_remove", sessionId, index)
+	return results, err
+}
+
+func (a *APIClient) SimulateTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
+	var result *SimulateTransactionResult
+	err := a.call(ctx, &result, "suavex_simulateTransaction", sessionId, tx)
+	return result, err
+}
+
+func (a *APIClient) SimulateBundle(ctx context.Context, sessionId string, bundle *Bundle) (*SimulateBundleResult, error) {
+	var result *SimulateBundleResult
+	err := a.call(ctx, &result, "suavex_simulateBundle", sessionId, bundle)
+	return result, err
+}
+
+func (a *APIClient) SimulateBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
+	var receipt []*SimulateBundleResult
+	err := a.call(ctx, &receipt, "suavex_simulateBundles", sessionId, bundles)
+	return receipt, err
+}
+
+func (a *APIClient) CheckBundleConflict(ctx context.Context, sessionId string, bundleA, bundleB common.Hash) (*BundleConflict, error) {
+	var conflict *BundleConflict
+	err := a.call(ctx, &conflict, "suavex_checkBundleConflict", sessionId, bundleA, bundleB)
+	return conflict, err
+}
+
+func (a *APIClient) MergeBundles(ctx context.Context, sessionId string, bundles []*Bundle, strategy *MergeStrategy) (*MergeBundlesResult, error) {
+	var result *MergeBundlesResult
+	err := a.call(ctx, &result, "suavex_mergeBundles", sessionId, bundles, strategy)
+	return result, err
+}
+
+func (a *APIClient) MergeStoredBundles(ctx context.Context, sessionId string, strategy *MergeStrategy) (*MergeBundlesResult, error) {
+	var result *MergeBundlesResult
+	err := a.call(ctx, &result, "suavex_mergeStoredBundles", sessionId, strategy)
+	return result, err
+}
+
+func (a *APIClient) SendBundle(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	var hash common.Hash
+	err := a.call(ctx, &hash, "suavex_sendBundle", bundle)
+	return hash, err
+}
+
+func (a *APIClient) GetBundleStatus(ctx context.Context, hash common.Hash) (*BundleStatus, error) {
+	var status *BundleStatus
+	err := a.call(ctx, &status, "suavex_getBundleStatus", hash)
+	return status, err
+}
+
+func (a *APIClient) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlock *big.Int) (common.Hash, error) {
+	var hash common.Hash
+	err := a.call(ctx, &hash, "suavex_sendPrivateTransaction", tx, maxBlock)
+	return hash, err
+}
+
+func (a *APIClient) CancelPrivateTransaction(ctx context.Context, hash common.Hash) error {
+	return a.call(ctx, nil, "suavex_cancelPrivateTransaction", hash)
+}
+
+func (a *APIClient) FillPending(ctx context.Context, sessionId string, opts *FillPendingOpts) (*FillPendingResult, error) {
+	var result *FillPendingResult
+	err := a.call(ctx, &result, "suavex_fillPending", sessionId, opts)
+	return result, err
+}
+
+func (a *APIClient) ExportSession(ctx context.Context, sessionId string) (*SessionDump, error) {
+	var dump *SessionDump
+	err := a.call(ctx, &dump, "suavex_exportSession", sessionId)
+	return dump, err
+}
+
+func (a *APIClient) ImportSession(ctx context.Context, dump *SessionDump) (*ImportSessionResult, error) {
+	var result *ImportSessionResult
+	err := a.call(ctx, &result, "suavex_importSession", dump)
+	return result, err
+}
+
+func (a *APIClient) GetSessionInfo(ctx context.Context, sessionId string) (*SessionInfo, error) {
+	var info *SessionInfo
+	err := a.call(ctx, &info, "suavex_getSessionInfo", sessionId)
+	return info, err
+}
+
 func (a *APIClient) BuildBlock(ctx context.Context, sessionId string) error {
-	return a.rpc.CallContext(ctx, nil, "suavex_buildBlock", sessionId)
+	return a.call(ctx, nil, "suavex_buildBlock", sessionId)
 }
 
 func (a *APIClient) Bid(ctx context.Context, sessioId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error) {
 	var req *SubmitBlockRequest
-	err := a.rpc.CallContext(ctx, &req, "suavex_bid", sessioId, blsPubKey)
+	err := a.call(ctx, &req, "suavex_bid", sessioId, blsPubKey)
 	return req, err
 }
 
 func (a *APIClient) GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error) {
 	var balance *big.Int
-	err := a.rpc.CallContext(ctx, &balance, "suavex_getBalance", sessionId, addr)
+	err := a.call(ctx, &balance, "suavex_getBalance", sessionId, addr)
 	return balance, err
 }
 
 func (a *APIClient) Call(ctx context.Context, sessionId string, transactionArgs *ethapi.TransactionArgs) (hexutil.Bytes, error) {
 	var result []byte
-	err := a.rpc.CallContext(ctx, result, "suavex_call", sessionId, transactionArgs)
+	err := a.call(ctx, result, "suavex_call", sessionId, transactionArgs)
 	return result, err
 }
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/common"
@@ -16,13 +17,30 @@ var _ API = (*Server)(nil)
 // SessionManager is the backend that manages the session state of the builder API.
 type SessionManager interface {
 	NewSession(context.Context, *BuildBlockArgs) (string, error)
-	AddTransaction(sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error)
-	AddTransactions(sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error)
-	AddBundles(sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error)
-	BuildBlock(sessionId string) error
-	Bid(sessionId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error)
-	GetBalance(sessionId string, addr common.Address) (*big.Int, error)
-	Call(sessionId string, transactionArgs *ethapi.TransactionArgs) ([]byte, error)
+	NewSessionFromBlock(ctx context.Context, blockHash common.Hash, opts *NewSessionFromBlockOpts) (string, error)
+	AddTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error)
+	AddTransactions(ctx context.Context, sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error)
+	AddBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error)
+	InsertAt(ctx context.Context, sessionId string, index uint64, item TxsOrBundle) ([]*SessionItemResult, error)
+	Remove(ctx context.Context, sessionId string, index uint64) ([]*SessionItemResult, error)
+	SimulateTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error)
+	SimulateBundle(ctx context.Context, sessionId string, bundle *Bundle) (*SimulateBundleResult, error)
+	SimulateBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error)
+	CheckBundleConflict(ctx context.Context, sessionId string, bundleA, bundleB common.Hash) (*BundleConflict, error)
+	MergeBundles(ctx context.Context, sessionId string, bundles []*Bundle, strategy *MergeStrategy) (*MergeBundlesResult, error)
+	MergeStoredBundles(ctx context.Context, sessionId string, strategy *MergeStrategy) (*MergeBundlesResult, error)
+	SendBundle(ctx context.Context, bundle *Bundle) (common.Hash, error)
+	GetBundleStatus(ctx context.Context, hash common.Hash) (*BundleStatus, error)
+	SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlock *big.Int) (common.Hash, error)
+	CancelPrivateTransaction(ctx context.Context, hash common.Hash) error
+	FillPending(ctx context.Context, sessionId string, opts *FillPendingOpts) (*FillPendingResult, error)
+	ExportSession(ctx context.Context, sessionId string) (*SessionDump, error)
+	ImportSession(ctx context.Context, dump *SessionDump) (*ImportSessionResult, error)
+	GetSessionInfo(ctx context.Context, sessionId string) (*SessionInfo, error)
+	BuildBlock(ctx context.Context, sessionId string) error
+	Bid(ctx context.Context, sessionId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error)
+	GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error)
+	Call(ctx context.Context, sessionId string, transactionArgs *ethapi.TransactionArgs) ([]byte, error)
 }
 
 func NewServer(s SessionManager) *Server {
@@ -37,41 +55,139 @@ type Server struct {
 }
 
 func (s *Server) NewSession(ctx context.Context, args *BuildBlockArgs) (string, error) {
-	return s.sessionMngr.NewSession(ctx, args)
+	defer updateMethodTimer("newSession", time.Now())
+	return withRPCError(s.sessionMngr.NewSession(ctx, args))
+}
+
+func (s *Server) NewSessionFromBlock(ctx context.Context, blockHash common.Hash, opts *NewSessionFromBlockOpts) (string, error) {
+	defer updateMethodTimer("newSessionFromBlock", time.Now())
+	return withRPCError(s.sessionMngr.NewSessionFromBlock(ctx, blockHash, opts))
 }
 
 func (s *Server) AddTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
-	return s.sessionMngr.AddTransaction(sessionId, tx)
+	defer updateMethodTimer("addTransaction", time.Now())
+	return withRPCError(s.sessionMngr.AddTransaction(ctx, sessionId, tx))
 }
 
 func (s *Server) AddTransactions(ctx context.Context, sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error) {
-	return s.sessionMngr.AddTransactions(sessionId, txs)
+	defer updateMethodTimer("addTransactions", time.Now())
+	return withRPCError(s.sessionMngr.AddTransactions(ctx, sessionId, txs))
 }
 
 func (s *Server) AddBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
-	return s.sessionMngr.AddBundles(sessionId, bundles)
+	defer updateMethodTimer("addBundles", time.Now())
+	return withRPCError(s.sessionMngr.AddBundles(ctx, sessionId, bundles))
+}
+
+func (s *Server) InsertAt(ctx context.Context, sessionId string, index uint64, item TxsOrBundle) ([]*SessionItemResult, error) {
+	defer updateMethodTimer("insertAt", time.Now())
+	return withRPCError(s.sessionMngr.InsertAt(ctx, sessionId, index, item))
+}
+
+func (s *Server) Remove(ctx context.Context, sessionId string, index uint64) ([]*SessionItemResult, error) {
+	defer updateMethodTimer("remove", time.Now())
+	return withRPCError(s.sessionMngr.Remove(ctx, sessionId, index))
+}
+
+func (s *Server) SimulateTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
+	defer updateMethodTimer("simulateTransaction", time.Now())
+	return withRPCError(s.sessionMngr.SimulateTransaction(ctx, sessionId, tx))
+}
+
+func (s *Server) SimulateBundle(ctx context.Context, sessionId string, bundle *Bundle) (*SimulateBundleResult, error) {
+	defer updateMethodTimer("simulateBundle", time.Now())
+	return withRPCError(s.sessionMngr.SimulateBundle(ctx, sessionId, bundle))
+}
+
+func (s *Server) SimulateBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
+	defer updateMethodTimer("simulateBundles", time.Now())
+	return withRPCError(s.sessionMngr.SimulateBundles(ctx, sessionId, bundles))
+}
+
+func (s *Server) CheckBundleConflict(ctx context.Context, sessionId string, bundleA, bundleB common.Hash) (*BundleConflict, error) {
+	defer updateMethodTimer("checkBundleConflict", time.Now())
+	return withRPCError(s.sessionMngr.CheckBundleConflict(ctx, sessionId, bundleA, bundleB))
+}
+
+func (s *Server) MergeBundles(ctx context.Context, sessionId string, bundles []*Bundle, strategy *MergeStrategy) (*MergeBundlesResult, error) {
+	defer updateMethodTimer("mergeBundles", time.Now())
+	return withRPCError(s.sessionMngr.MergeBundles(ctx, sessionId, bundles, strategy))
+}
+
+func (s *Server) MergeStoredBundles(ctx context.Context, sessionId string, strategy *MergeStrategy) (*MergeBundlesResult, error) {
+	defer updateMethodTimer("mergeStoredBundles", time.Now())
+	return withRPCError(s.sessionMngr.MergeStoredBundles(ctx, sessionId, strategy))
+}
+
+func (s *Server) SendBundle(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	defer updateMethodTimer("sendBundle", time.Now())
+	return withRPCError(s.sessionMngr.SendBundle(ctx, bundle))
+}
+
+func (s *Server) GetBundleStatus(ctx context.Context, hash common.Hash) (*BundleStatus, error) {
+	defer updateMethodTimer("getBundleStatus", time.Now())
+	return withRPCError(s.sessionMngr.GetBundleStatus(ctx, hash))
+}
+
+func (s *Server) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlock *big.Int) (common.Hash, error) {
+	defer updateMethodTimer("sendPrivateTransaction", time.Now())
+	return withRPCError(s.sessionMngr.SendPrivateTransaction(ctx, tx, maxBlock))
+}
+
+func (s *Server) CancelPrivateTransaction(ctx context.Context, hash common.Hash) error {
+	defer updateMethodTimer("cancelPrivateTransaction", time.Now())
+	return ToRPCError(s.sessionMngr.CancelPrivateTransaction(ctx, hash))
+}
+
+func (s *Server) FillPending(ctx context.Context, sessionId string, opts *FillPendingOpts) (*FillPendingResult, error) {
+	defer updateMethodTimer("fillPending", time.Now())
+	return withRPCError(s.sessionMngr.FillPending(ctx, sessionId, opts))
+}
+
+func (s *Server) ExportSession(ctx context.Context, sessionId string) (*SessionDump, error) {
+	defer updateMethodTimer("exportSession", time.Now())
+	return withRPCError(s.sessionMngr.ExportSession(ctx, sessionId))
+}
+
+func (s *Server) ImportSession(ctx context.Context, dump *SessionDump) (*ImportSessionResult, error) {
+	defer updateMethodTimer("importSession", time.Now())
+	return withRPCError(s.sessionMngr.ImportSession(ctx, dump))
+}
+
+func (s *Server) GetSessionInfo(ctx context.Context, sessionId string) (*SessionInfo, error) {
+	defer updateMethodTimer("getSessionInfo", time.Now())
+	return withRPCError(s.sessionMngr.GetSessionInfo(ctx, sessionId))
 }
 
 func (s *Server) BuildBlock(ctx context.Context, sessionId string) error {
-	return s.sessionMngr.BuildBlock(sessionId)
+	defer updateMethodTimer("buildBlock", time.Now())
+	return ToRPCError(s.sessionMngr.BuildBlock(ctx, sessionId))
 }
 
 func (s *Server) Bid(ctx context.Context, sessionId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error) {
-	return s.sessionMngr.Bid(sessionId, blsPubKey)
+	defer updateMethodTimer("bid", time.Now())
+	return withRPCError(s.sessionMngr.Bid(ctx, sessionId, blsPubKey))
 }
 
 func (s *Server) GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error) {
-	return s.sessionMngr.GetBalance(sessionId, addr)
+	defer updateMethodTimer("getBalance", time.Now())
+	return withRPCError(s.sessionMngr.GetBalance(ctx, sessionId, addr))
 }
 
 func (s *Server) Call(ctx context.Context, sessionId string, transactionArgs *ethapi.TransactionArgs) (hexutil.Bytes, error) {
-	res, err := s.sessionMngr.Call(sessionId, transactionArgs)
+	defer updateMethodTimer("call", time.Now())
+	res, err := s.sessionMngr.Call(ctx, sessionId, transactionArgs)
 	if err != nil {
-		return nil, err
+		return nil, ToRPCError(err)
 	}
 	return hexutil.Bytes(res), nil
 }
 
+// withRPCError converts the error of a session manager call with ToRPCError.
+func withRPCError[T any](res T, err error) (T, error) {
+	return res, ToRPCError(err)
+}
+
 // TODO: Remove
 type MockServer struct {
 }
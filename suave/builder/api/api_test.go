@@ -2,11 +2,14 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -25,6 +28,10 @@ func TestAPI(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, res0, "1")
 
+	res0, err = c.NewSessionFromBlock(context.Background(), common.Hash{}, &NewSessionFromBlockOpts{TxIndex: 1})
+	require.NoError(t, err)
+	require.Equal(t, res0, "1")
+
 	txn := types.NewTransaction(0, common.Address{}, big.NewInt(1), 1, big.NewInt(1), []byte{})
 	_, err = c.AddTransaction(context.Background(), "1", txn)
 	require.NoError(t, err)
@@ -40,6 +47,114 @@ func TestAPI(t *testing.T) {
 	}
 	_, err = c.AddBundles(context.Background(), "1", []*Bundle{bundle})
 	require.NoError(t, err)
+
+	_, err = c.InsertAt(context.Background(), "1", 0, TxsOrBundle{Txs: types.Transactions{txn}})
+	require.NoError(t, err)
+
+	_, err = c.InsertAt(context.Background(), "1", 1, TxsOrBundle{Bundle: bundle})
+	require.NoError(t, err)
+
+	_, err = c.Remove(context.Background(), "1", 1)
+	require.NoError(t, err)
+
+	_, err = c.SimulateTransaction(context.Background(), "1", txn)
+	require.NoError(t, err)
+
+	_, err = c.SimulateBundle(context.Background(), "1", bundle)
+	require.NoError(t, err)
+
+	_, err = c.SimulateBundles(context.Background(), "1", []*Bundle{bundle})
+	require.NoError(t, err)
+
+	_, err = c.CheckBundleConflict(context.Background(), "1", bundle.Hash(), bundle.Hash())
+	require.NoError(t, err)
+
+	_, err = c.MergeBundles(context.Background(), "1", []*Bundle{bundle}, &MergeStrategy{Name: MergeStrategyGreedyEgp})
+	require.NoError(t, err)
+
+	_, err = c.MergeStoredBundles(context.Background(), "1", nil)
+	require.NoError(t, err)
+
+	hash, err := c.SendBundle(context.Background(), bundle)
+	require.NoError(t, err)
+	require.Equal(t, bundle.Hash(), hash)
+
+	status, err := c.GetBundleStatus(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, BundleStatusPending, status.Status)
+
+	hash, err = c.SendPrivateTransaction(context.Background(), txn, big.NewInt(10))
+	require.NoError(t, err)
+	require.Equal(t, txn.Hash(), hash)
+
+	err = c.CancelPrivateTransaction(context.Background(), hash)
+	require.NoError(t, err)
+
+	_, err = c.FillPending(context.Background(), "1", &FillPendingOpts{MaxTxs: 1})
+	require.NoError(t, err)
+
+	dump, err := c.ExportSession(context.Background(), "1")
+	require.NoError(t, err)
+
+	_, err = c.ImportSession(context.Background(), dump)
+	require.NoError(t, err)
+
+	info, err := c.GetSessionInfo(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, "1", info.SessionId)
+}
+
+func TestAPI_Errors(t *testing.T) {
+	srv := rpc.NewServer()
+	srv.RegisterName("suavex", NewServer(&errSessionManager{}))
+	c := NewClientFromRPC(rpc.DialInProc(srv))
+
+	// errors of the package keep their code, message and data
+	_, err := c.GetBalance(context.Background(), "1", common.Address{})
+	require.ErrorIs(t, err, ErrSessionNotFound)
+	require.NotErrorIs(t, err, ErrSessionInvalidated)
+	require.Equal(t, "session not found: 1", err.Error())
+
+	var rerr rpc.DataError
+	require.True(t, errors.As(err, &rerr))
+	require.Equal(t, map[string]interface{}{"sessionId": "1"}, rerr.ErrorData())
+
+	// core errors get the code of their class
+	err = c.BuildBlock(context.Background(), "1")
+	require.ErrorIs(t, err, ErrNonceTooLow)
+	require.Contains(t, err.Error(), "nonce too low")
+
+	// other errors are left as is
+	_, err = c.Call(context.Background(), "1", nil)
+	require.Error(t, err)
+	require.False(t, errors.As(err, new(*Error)))
+}
+
+// errSessionManager fails the calls with the errors of the different kinds.
+type errSessionManager struct {
+	nullSessionManager
+}
+
+func (errSessionManager) GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error) {
+	return nil, fmt.Errorf("%w: %s", ErrSessionNotFound.WithData(&SessionErrorData{SessionId: sessionId}), sessionId)
+}
+
+func (errSessionManager) BuildBlock(ctx context.Context, sessionId string) error {
+	return fmt.Errorf("could not apply tx 0: %w", core.ErrNonceTooLow)
+}
+
+func (errSessionManager) Call(ctx context.Context, sessionId string, args *ethapi.TransactionArgs) ([]byte, error) {
+	return nil, errors.New("call failed")
+}
+
+func TestErrorCode(t *testing.T) {
+	require.Equal(t, CodeInvalidBundle, ErrorCode(fmt.Errorf("%w: empty transactions", ErrInvalidBundle)))
+	require.Equal(t, CodeInsufficientFunds, ErrorCode(fmt.Errorf("transfer: %w", core.ErrInsufficientFundsForTransfer)))
+	require.Equal(t, CodeExecutionFailed, ErrorCode(errors.New("execution failed")))
+
+	res := &SimulateTransactionResult{Error: "nonce too high", ErrorCode: CodeNonceTooHigh}
+	require.ErrorIs(t, res.Err(), ErrNonceTooHigh)
+	require.NoError(t, (&SimulateTransactionResult{Success: true}).Err())
 }
 
 type nullSessionManager struct{}
@@ -48,30 +163,101 @@ func (nullSessionManager) NewSession(ctx context.Context, args *BuildBlockArgs)
 	return "1", ctx.Err()
 }
 
-func (nullSessionManager) AddTransaction(sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
+func (nullSessionManager) NewSessionFromBlock(ctx context.Context, blockHash common.Hash, opts *NewSessionFromBlockOpts) (string, error) {
+	return "1", ctx.Err()
+}
+
+func (nullSessionManager) AddTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
 	return &SimulateTransactionResult{Logs: []*SimulatedLog{}}, nil
 }
 
-func (nullSessionManager) AddTransactions(sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error) {
+func (nullSessionManager) AddTransactions(ctx context.Context, sessionId string, txs types.Transactions) ([]*SimulateTransactionResult, error) {
+	return nil, nil
+}
+
+func (nullSessionManager) AddBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
+	return nil, nil
+}
+
+func (nullSessionManager) InsertAt(ctx context.Context, sessionId string, index uint64, item TxsOrBundle) ([]*SessionItemResult, error) {
+	if item.Bundle == nil && len(item.Txs) == 0 {
+		return nil, errors.New("empty item")
+	}
+	return nil, nil
+}
+
+func (nullSessionManager) Remove(ctx context.Context, sessionId string, index uint64) ([]*SessionItemResult, error) {
 	return nil, nil
 }
 
-func (nullSessionManager) AddBundles(sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
+func (nullSessionManager) SimulateTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*SimulateTransactionResult, error) {
+	return &SimulateTransactionResult{Logs: []*SimulatedLog{}}, nil
+}
+
+func (nullSessionManager) SimulateBundle(ctx context.Context, sessionId string, bundle *Bundle) (*SimulateBundleResult, error) {
+	return &SimulateBundleResult{}, nil
+}
+
+func (nullSessionManager) SimulateBundles(ctx context.Context, sessionId string, bundles []*Bundle) ([]*SimulateBundleResult, error) {
 	return nil, nil
 }
 
-func (nullSessionManager) BuildBlock(sessionId string) error {
+func (nullSessionManager) CheckBundleConflict(ctx context.Context, sessionId string, bundleA, bundleB common.Hash) (*BundleConflict, error) {
+	return &BundleConflict{}, nil
+}
+
+func (nullSessionManager) MergeBundles(ctx context.Context, sessionId string, bundles []*Bundle, strategy *MergeStrategy) (*MergeBundlesResult, error) {
+	return &MergeBundlesResult{}, nil
+}
+
+func (nullSessionManager) MergeStoredBundles(ctx context.Context, sessionId string, strategy *MergeStrategy) (*MergeBundlesResult, error) {
+	return &MergeBundlesResult{}, nil
+}
+
+func (nullSessionManager) SendBundle(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	return bundle.Hash(), nil
+}
+
+func (nullSessionManager) GetBundleStatus(ctx context.Context, hash common.Hash) (*BundleStatus, error) {
+	return &BundleStatus{Hash: hash, Status: BundleStatusPending}, nil
+}
+
+func (nullSessionManager) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlock *big.Int) (common.Hash, error) {
+	return tx.Hash(), nil
+}
+
+func (nullSessionManager) CancelPrivateTransaction(ctx context.Context, hash common.Hash) error {
+	return nil
+}
+
+func (nullSessionManager) FillPending(ctx context.Context, sessionId string, opts *FillPendingOpts) (*FillPendingResult, error) {
+	return &FillPendingResult{}, nil
+}
+
+func (nullSessionManager) ExportSession(ctx context.Context, sessionId string) (*SessionDump, error) {
+	return &SessionDump{Args: &BuildBlockArgs{}, Calls: []*SessionCall{}}, nil
+}
+
+func (nullSessionManager) ImportSession(ctx context.Context, dump *SessionDump) (*ImportSessionResult, error) {
+	return &ImportSessionResult{SessionId: "1"}, ctx.Err()
+}
+
+func (nullSessionManager) GetSessionInfo(ctx context.Context, sessionId string) (*SessionInfo, error) {
+	return &SessionInfo{SessionId: sessionId}, nil
+}
+
+func (nullSessionManager) BuildBlock(ctx context.Context, sessionId string) error {
 	return nil
 }
 
-func (nullSessionManager) Bid(sessioId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error) {
+func (nullSessionManager) Bid(ctx context.Context, sessioId string, blsPubKey phase0.BLSPubKey) (*SubmitBlockRequest, error) {
 	return nil, nil
 }
 
-func (nullSessionManager) GetBalance(sessionId string, addr common.Address) (*big.Int, error) {
+func (nullSessionManager) GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error) {
 	return big.NewInt(0), nil
 }
 
-func (nullSessionManager) Call(sessionId string, args *ethapi.TransactionArgs) ([]byte, error) {
+func (nullSessionManager) Call(ctx context.Context, sessionId string, args *ethapi.TransactionArgs) ([]byte, error) {
 	return nil, nil
 }
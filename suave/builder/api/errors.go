@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Error codes of the suavex namespace. The codes are grouped by class:
+// session lifecycle (-39000), capacity (-39100), validation (-39200)
+// and execution (-39300).
+const (
+	CodeSessionNotFound    = -39000
+	CodeSessionInvalidated = -39001
+	CodeBlockNotBuilt      = -39002
+	CodeSessionForbidden   = -39003
+	CodeUnauthenticated    = -39004
+
+	CodeTooManySessions = -39100
+	CodeUnavailable     = -39101
+	CodeBudgetExceeded  = -39102
+	CodePoolFull        = -39103
+
+	CodeInvalidParams  = -39200
+	CodeInvalidBundle  = -39201
+	CodeBundleNotFound = -39202
+	CodeTxNotFound     = -39203
+
+	CodeExecutionFailed   = -39300
+	CodeBuildInterrupted  = -39301
+	CodeConstraintNotMet  = -39302
+	CodeDeniedAddress     = -39303
+	CodeNonceTooLow       = -39304
+	CodeNonceTooHigh      = -39305
+	CodeInsufficientFunds = -39306
+	CodeGasLimitReached   = -39307
+	CodeIntrinsicGas      = -39308
+	CodeFeeCapTooLow      = -39309
+
+	minCode = -39399
+	maxCode = -39000
+)
+
+var (
+	ErrSessionNotFound    = &Error{Code: CodeSessionNotFound, Message: "session not found"}
+	ErrSessionInvalidated = &Error{Code: CodeSessionInvalidated, Message: "session invalidated by a change of its parent"}
+	ErrBlockNotBuilt      = &Error{Code: CodeBlockNotBuilt, Message: "block not built"}
+	ErrSessionForbidden   = &Error{Code: CodeSessionForbidden, Message: "session owned by another client"}
+	ErrUnauthenticated    = &Error{Code: CodeUnauthenticated, Message: "sessions require an authenticated client"}
+
+	ErrTooManySessions = &Error{Code: CodeTooManySessions, Message: "too many sessions"}
+	ErrUnavailable     = &Error{Code: CodeUnavailable, Message: "not available"}
+	ErrBudgetExceeded  = &Error{Code: CodeBudgetExceeded, Message: "budget exceeded"}
+	ErrPoolFull        = &Error{Code: CodePoolFull, Message: "pool full"}
+
+	ErrInvalidParams  = &Error{Code: CodeInvalidParams, Message: "invalid params"}
+	ErrInvalidBundle  = &Error{Code: CodeInvalidBundle, Message: "invalid bundle"}
+	ErrBundleNotFound = &Error{Code: CodeBundleNotFound, Message: "bundle not found"}
+	ErrTxNotFound     = &Error{Code: CodeTxNotFound, Message: "transaction not found"}
+
+	ErrExecutionFailed   = &Error{Code: CodeExecutionFailed, Message: "execution failed"}
+	ErrBuildInterrupted  = &Error{Code: CodeBuildInterrupted, Message: "block building interrupted"}
+	ErrConstraintNotMet  = &Error{Code: CodeConstraintNotMet, Message: "bundle constraint not met"}
+	ErrDeniedAddress     = &Error{Code: CodeDeniedAddress, Message: "transaction touches a denied address"}
+	ErrNonceTooLow       = &Error{Code: CodeNonceTooLow, Message: core.ErrNonceTooLow.Error()}
+	ErrNonceTooHigh      = &Error{Code: CodeNonceTooHigh, Message: core.ErrNonceTooHigh.Error()}
+	ErrInsufficientFunds = &Error{Code: CodeInsufficientFunds, Message: core.ErrInsufficientFunds.Error()}
+	ErrGasLimitReached   = &Error{Code: CodeGasLimitReached, Message: core.ErrGasLimitReached.Error()}
+	ErrIntrinsicGas      = &Error{Code: CodeIntrinsicGas, Message: core.ErrIntrinsicGas.Error()}
+	ErrFeeCapTooLow      = &Error{Code: CodeFeeCapTooLow, Message: core.ErrFeeCapTooLow.Error()}
+)
+
+// coreErrors are the codes of the transaction errors of the core package,
+// which cannot wrap the errors of this package.
+var coreErrors = []struct {
+	err  error
+	code int
+}{
+	{core.ErrNonceTooLow, CodeNonceTooLow},
+	{core.ErrNonceTooHigh, CodeNonceTooHigh},
+	{core.ErrInsufficientFunds, CodeInsufficientFunds},
+	{core.ErrInsufficientFundsForTransfer, CodeInsufficientFunds},
+	{core.ErrGasLimitReached, CodeGasLimitReached},
+	{core.ErrIntrinsicGas, CodeIntrinsicGas},
+	{core.ErrFeeCapTooLow, CodeFeeCapTooLow},
+}
+
+var (
+	_ rpc.Error     = (*Error)(nil)
+	_ rpc.DataError = (*Error)(nil)
+)
+
+// Error is an error of the suavex API. It is sent with its code and data over
+// JSON-RPC, and decoded back by the APIClient. Errors match with errors.Is
+// when their codes are equal, whatever their message and data.
+type Error struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) ErrorCode() int {
+	return e.Code
+}
+
+func (e *Error) ErrorData() interface{} {
+	return e.Data
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithData returns a copy of the error with the given data.
+func (e *Error) WithData(data interface{}) *Error {
+	cpy := *e
+	cpy.Data = data
+	return &cpy
+}
+
+// SessionErrorData is the data of the errors about a session.
+type SessionErrorData struct {
+	SessionId string `json:"sessionId"`
+}
+
+// ResourceErrorData is the data of the capacity errors, with the resource that
+// ran out or is not available.
+type ResourceErrorData struct {
+	Resource  string `json:"resource"`
+	SessionId string `json:"sessionId,omitempty"` // session that ran out of its budget, if any
+}
+
+// HashErrorData is the data of the validation and execution errors about a
+// bundle, a transaction or a block, with its hash.
+type HashErrorData struct {
+	Hash common.Hash `json:"hash"`
+}
+
+// ErrorCode returns the code of the error, CodeExecutionFailed if the error has
+// none. It is the code reported in the simulation results.
+func ErrorCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	for _, c := range coreErrors {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return CodeExecutionFailed
+}
+
+// ToRPCError returns the error to send over JSON-RPC, with the code and the data
+// of the Error it wraps and the full message. The errors without code are
+// returned as is.
+func ToRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return &Error{Code: e.Code, Message: err.Error(), Data: e.Data}
+	}
+	for _, c := range coreErrors {
+		if errors.Is(err, c.err) {
+			return &Error{Code: c.code, Message: err.Error()}
+		}
+	}
+	return err
+}
+
+// fromRPCError returns the Error of a JSON-RPC error with a suavex code, so
+// that it matches the errors of this package with errors.Is.
+func fromRPCError(err error) error {
+	var rerr rpc.Error
+	if !errors.As(err, &rerr) || rerr.ErrorCode() < minCode || rerr.ErrorCode() > maxCode {
+		return err
+	}
+	e := &Error{Code: rerr.ErrorCode(), Message: rerr.Error()}
+	if derr, ok := rerr.(rpc.DataError); ok {
+		e.Data = derr.ErrorData()
+	}
+	return e
+}
+
+// Err returns the error of a failed transaction, nil if it succeeded.
+func (s *SimulateTransactionResult) Err() error {
+	if s.Success {
+		return nil
+	}
+	return &Error{Code: s.ErrorCode, Message: s.Error}
+}
+
+// Err returns the error of a failed bundle, nil if it succeeded.
+func (s *SimulateBundleResult) Err() error {
+	if s.Success {
+		return nil
+	}
+	return &Error{Code: s.ErrorCode, Message: s.Error}
+}
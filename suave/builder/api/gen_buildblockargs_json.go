@@ -23,7 +23,10 @@ func (b BuildBlockArgs) MarshalJSON() ([]byte, error) {
 		GasLimit       hexutil.Uint64      `json:"gasLimit"`
 		Random         common.Hash         `json:"random"`
 		Withdrawals    []*types.Withdrawal `json:"withdrawals"`
+		BeaconRoot     *common.Hash        `json:"beaconRoot"`
 		Extra          hexutil.Bytes       `json:"extra"`
+		ParentSession  string              `json:"parentSession,omitempty"`
+		Record         bool                `json:"record,omitempty"`
 	}
 	var enc BuildBlockArgs
 	enc.Slot = hexutil.Uint64(b.Slot)
@@ -34,7 +37,10 @@ func (b BuildBlockArgs) MarshalJSON() ([]byte, error) {
 	enc.GasLimit = hexutil.Uint64(b.GasLimit)
 	enc.Random = b.Random
 	enc.Withdrawals = b.Withdrawals
+	enc.BeaconRoot = b.BeaconRoot
 	enc.Extra = b.Extra
+	enc.ParentSession = b.ParentSession
+	enc.Record = b.Record
 	return json.Marshal(&enc)
 }
 
@@ -49,7 +55,10 @@ func (b *BuildBlockArgs) UnmarshalJSON(input []byte) error {
 		GasLimit       *hexutil.Uint64     `json:"gasLimit"`
 		Random         *common.Hash        `json:"random"`
 		Withdrawals    []*types.Withdrawal `json:"withdrawals"`
+		BeaconRoot     *common.Hash        `json:"beaconRoot"`
 		Extra          *hexutil.Bytes      `json:"extra"`
+		ParentSession  *string             `json:"parentSession,omitempty"`
+		Record         *bool               `json:"record,omitempty"`
 	}
 	var dec BuildBlockArgs
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -79,8 +88,17 @@ func (b *BuildBlockArgs) UnmarshalJSON(input []byte) error {
 	if dec.Withdrawals != nil {
 		b.Withdrawals = dec.Withdrawals
 	}
+	if dec.BeaconRoot != nil {
+		b.BeaconRoot = dec.BeaconRoot
+	}
 	if dec.Extra != nil {
 		b.Extra = *dec.Extra
 	}
+	if dec.ParentSession != nil {
+		b.ParentSession = *dec.ParentSession
+	}
+	if dec.Record != nil {
+		b.Record = *dec.Record
+	}
 	return nil
 }
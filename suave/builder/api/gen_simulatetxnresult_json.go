@@ -13,26 +13,35 @@ var _ = (*simulateTransactionResultMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (s SimulateTransactionResult) MarshalJSON() ([]byte, error) {
 	type SimulateTransactionResult struct {
-		Egp     hexutil.Uint64  `json:"egp"`
-		Logs    []*SimulatedLog `json:"logs"`
-		Success bool            `json:"success"`
-		Error   string          `json:"error"`
+		Egp       hexutil.Uint64  `json:"egp"`
+		Logs      []*SimulatedLog `json:"logs"`
+		Success   bool            `json:"success"`
+		Error     string          `json:"error"`
+		ErrorCode int             `json:"errorCode,omitempty"`
+		Reads     *StateAccess    `json:"reads,omitempty"`
+		Writes    *StateAccess    `json:"writes,omitempty"`
 	}
 	var enc SimulateTransactionResult
 	enc.Egp = hexutil.Uint64(s.Egp)
 	enc.Logs = s.Logs
 	enc.Success = s.Success
 	enc.Error = s.Error
+	enc.ErrorCode = s.ErrorCode
+	enc.Reads = s.Reads
+	enc.Writes = s.Writes
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (s *SimulateTransactionResult) UnmarshalJSON(input []byte) error {
 	type SimulateTransactionResult struct {
-		Egp     *hexutil.Uint64 `json:"egp"`
-		Logs    []*SimulatedLog `json:"logs"`
-		Success *bool           `json:"success"`
-		Error   *string         `json:"error"`
+		Egp       *hexutil.Uint64 `json:"egp"`
+		Logs      []*SimulatedLog `json:"logs"`
+		Success   *bool           `json:"success"`
+		Error     *string         `json:"error"`
+		ErrorCode *int            `json:"errorCode,omitempty"`
+		Reads     *StateAccess    `json:"reads,omitempty"`
+		Writes    *StateAccess    `json:"writes,omitempty"`
 	}
 	var dec SimulateTransactionResult
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -50,5 +59,14 @@ func (s *SimulateTransactionResult) UnmarshalJSON(input []byte) error {
 	if dec.Error != nil {
 		s.Error = *dec.Error
 	}
+	if dec.ErrorCode != nil {
+		s.ErrorCode = *dec.ErrorCode
+	}
+	if dec.Reads != nil {
+		s.Reads = dec.Reads
+	}
+	if dec.Writes != nil {
+		s.Writes = dec.Writes
+	}
 	return nil
 }
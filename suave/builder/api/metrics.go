@@ -0,0 +1,15 @@
+package api
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// methodTimerName is the prefix of the per-method latency timers.
+const methodTimerName = "suave/builder/api"
+
+// updateMethodTimer tracks the latency of a call to the given method.
+func updateMethodTimer(method string, start time.Time) {
+	metrics.GetOrRegisterTimer(methodTimerName+"/"+method, nil).UpdateSince(start)
+}
@@ -0,0 +1,10 @@
+package builder
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	sessionOpenMeter   = metrics.NewRegisteredMeter("suave/builder/sessions/open", nil)
+	sessionExpireMeter = metrics.NewRegisteredMeter("suave/builder/sessions/expire", nil)
+	sessionCloseMeter  = metrics.NewRegisteredMeter("suave/builder/sessions/close", nil) // sessions ending other than by expiry, e.g. invalidated
+	liveSessionsGauge  = metrics.NewRegisteredGauge("suave/builder/sessions/live", nil)
+)
@@ -0,0 +1,188 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+// usageBudget returns the resources spent by a builder as a budget.
+func usageBudget(usage miner.BuilderUsage) *api.Budget {
+	return &api.Budget{
+		Gas:         usage.Gas,
+		Txs:         usage.Txs,
+		Bundles:     usage.Bundles,
+		CPUTime:     uint64(usage.CPUTime.Milliseconds()),
+		StateCopies: usage.StateCopies,
+	}
+}
+
+// addBudget adds the resources of b to a.
+func addBudget(a, b *api.Budget) {
+	a.Gas += b.Gas
+	a.Txs += b.Txs
+	a.Bundles += b.Bundles
+	a.CPUTime += b.CPUTime
+	a.StateCopies += b.StateCopies
+}
+
+// exhausted returns the first resource of the usage that reached its limit,
+// or an empty string if there is none.
+func exhausted(usage, limit *api.Budget) string {
+	switch {
+	case limit.Gas != 0 && usage.Gas >= limit.Gas:
+		return "gas"
+	case limit.Txs != 0 && usage.Txs >= limit.Txs:
+		return "transactions"
+	case limit.Bundles != 0 && usage.Bundles >= limit.Bundles:
+		return "bundles"
+	case limit.CPUTime != 0 && usage.CPUTime >= limit.CPUTime:
+		return "cpu time"
+	case limit.StateCopies != 0 && usage.StateCopies >= limit.StateCopies:
+		return "state copies"
+	}
+	return ""
+}
+
+// spentBudget is the resources spent by a session that is gone, closed or
+// expired or on-the-fly, at the time it went.
+type spentBudget struct {
+	time  time.Time
+	usage *api.Budget
+}
+
+// clientUsage returns the resources spent by the client: by its open sessions,
+// and by the ones gone in the last ClientBudgetWindow. The sessions lock must be
+// held.
+func (s *SessionManager) clientUsage(client string) *api.Budget {
+	usage := new(api.Budget)
+	for id, session := range s.sessions {
+		if s.owners[id] == client {
+			addBudget(usage, usageBudget(session.Usage()))
+		}
+	}
+	since := time.Now().Add(-s.config.ClientBudgetWindow)
+	for _, spent := range s.spent[client] {
+		if spent.time.After(since) {
+			addBudget(usage, spent.usage)
+		}
+	}
+	return usage
+}
+
+// addSpent keeps the resources spent by a session that is gone, so that they
+// still count against the budget of its client, and forgets the ones out of the
+// window of the client budgets. The sessions lock must be held.
+func (s *SessionManager) addSpent(client string, usage miner.BuilderUsage) {
+	now := time.Now()
+	since := now.Add(-s.config.ClientBudgetWindow)
+	for c, spent := range s.spent {
+		i := 0
+		for i < len(spent) && !spent[i].time.After(since) {
+			i++
+		}
+		if i == len(spent) {
+			delete(s.spent, c)
+		} else {
+			s.spent[c] = spent[i:]
+		}
+	}
+	s.spent[client] = append(s.spent[client], spentBudget{time: now, usage: usageBudget(usage)})
+}
+
+// endOnTheFly counts the resources spent by an on-the-fly session against the
+// budget of its client once the call is done. The open sessions are left alone.
+func (s *SessionManager) endOnTheFly(ctx context.Context, sessionId string, builder *miner.Builder) {
+	if sessionId != "" || builder == nil {
+		return
+	}
+	client, _ := s.caller(ctx)
+
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+	s.addSpent(client, builder.Usage())
+}
+
+// callGasCap returns the gas a call of the session can use, the gas left in
+// the budgets of the session and of its client. It is zero if neither limits
+// the gas.
+func (s *SessionManager) callGasCap(sessionId string) uint64 {
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+
+	session, ok := s.sessions[sessionId]
+	if !ok {
+		return 0
+	}
+	var gasCap uint64
+	limit := func(budget, used uint64) {
+		if budget == 0 {
+			return
+		}
+		// a budget spent meanwhile by another call still allows one unit of
+		// gas, which is not enough for any call
+		left := max(budget-min(used, budget), 1)
+		if gasCap == 0 || left < gasCap {
+			gasCap = left
+		}
+	}
+	limit(s.config.SessionBudget.Gas, session.Usage().Gas)
+	limit(s.config.ClientBudget.Gas, s.clientUsage(s.owners[sessionId]).Gas)
+	return gasCap
+}
+
+// checkBudget returns an error if the session or its client ran out of one of
+// their budgets. It is called before the calls simulating transactions, the
+// other calls, like the ones building the block, are still allowed. A call
+// runs to completion once started, so it can spend more than what is left.
+//
+// The errors about the session itself are left to getSession.
+func (s *SessionManager) checkBudget(ctx context.Context, sessionId string) error {
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+
+	client, _ := s.caller(ctx)
+	if sessionId != "" {
+		session, ok := s.sessions[sessionId]
+		if !ok || s.checkOwner(ctx, sessionId) != nil {
+			return nil
+		}
+		if res := exhausted(usageBudget(session.Usage()), &s.config.SessionBudget); res != "" {
+			return fmt.Errorf("%w: session %s", api.ErrBudgetExceeded.WithData(&api.ResourceErrorData{Resource: res, SessionId: sessionId}), res)
+		}
+		client = s.owners[sessionId]
+	}
+	if res := exhausted(s.clientUsage(client), &s.config.ClientBudget); res != "" {
+		return fmt.Errorf("%w: client %s", api.ErrBudgetExceeded.WithData(&api.ResourceErrorData{Resource: res}), res)
+	}
+	return nil
+}
+
+// GetSessionInfo returns the state of the session and the resources spent by
+// it and by the other open sessions of its client.
+func (s *SessionManager) GetSessionInfo(ctx context.Context, sessionId string) (*api.SessionInfo, error) {
+	builder, release, err := s.getSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+
+	sessionBudget, clientBudget := s.config.SessionBudget, s.config.ClientBudget
+	return &api.SessionInfo{
+		SessionId:    sessionId,
+		ParentHash:   builder.ParentHash(),
+		BlockNumber:  new(big.Int).Set(builder.BlockNumber()),
+		Txs:          uint64(len(builder.Transactions())),
+		Usage:        usageBudget(builder.Usage()),
+		Budget:       &sessionBudget,
+		ClientUsage:  s.clientUsage(s.owners[sessionId]),
+		ClientBudget: &clientBudget,
+	}, nil
+}
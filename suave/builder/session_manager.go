@@ -2,6 +2,7 @@ package builder
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -9,34 +10,100 @@ import (
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/privatepool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/ethereum/go-ethereum/suave/bundlestore"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var (
+	errBundleStoreUnavailable = fmt.Errorf("bundle store %w", api.ErrUnavailable.WithData(&api.ResourceErrorData{Resource: "bundle store"}))
+	errPrivatePoolUnavailable = fmt.Errorf("private transaction pool %w", api.ErrUnavailable.WithData(&api.ResourceErrorData{Resource: "private transaction pool"}))
+
+	ErrSessionInvalidated = api.ErrSessionInvalidated
+)
+
+// sessionError returns the error about the given session, with the session id
+// as data.
+func sessionError(err *api.Error, sessionId string) error {
+	return fmt.Errorf("%w: %s", err.WithData(&api.SessionErrorData{SessionId: sessionId}), sessionId)
+}
+
+// bundleError returns the error of the bundle store about the given bundle,
+// with its code and the hash of the bundle as data.
+func bundleError(err error, hash common.Hash) error {
+	var e *api.Error
+	switch {
+	case errors.Is(err, bundlestore.ErrStoreFull):
+		e = api.ErrPoolFull.WithData(&api.ResourceErrorData{Resource: "bundles"})
+	case errors.Is(err, bundlestore.ErrBundleNotFound):
+		e = api.ErrBundleNotFound.WithData(&api.HashErrorData{Hash: hash})
+	default:
+		e = api.ErrInvalidBundle.WithData(&api.HashErrorData{Hash: hash})
+	}
+	return fmt.Errorf("%w: %w", e, err)
+}
+
+// privateTxError returns the error of the private pool about the given
+// transaction, with its code and the hash of the transaction as data. The
+// errors of the transaction validation keep the code of their core error.
+func privateTxError(err error, hash common.Hash) error {
+	var e *api.Error
+	switch {
+	case errors.Is(err, privatepool.ErrPoolFull):
+		e = api.ErrPoolFull.WithData(&api.ResourceErrorData{Resource: "private transactions"})
+	case errors.Is(err, privatepool.ErrTxNotFound):
+		e = api.ErrTxNotFound.WithData(&api.HashErrorData{Hash: hash})
+	case api.ErrorCode(err) != api.CodeExecutionFailed:
+		e = &api.Error{Code: api.ErrorCode(err), Message: "invalid transaction", Data: &api.HashErrorData{Hash: hash}}
+	default:
+		e = api.ErrInvalidParams.WithData(&api.HashErrorData{Hash: hash})
+	}
+	return fmt.Errorf("%w: %w", e, err)
+}
+
 type Config struct {
 	GasCeil               uint64
 	SessionIdleTimeout    time.Duration
 	MaxConcurrentSessions int
+	DenyList              *miner.DenyList
+	Admins                []string      // clients allowed to access the sessions of every client
+	SessionBudget         api.Budget    // resources every session can spend, see checkBudget
+	ClientBudget          api.Budget    // resources the sessions of every client can spend together
+	ClientBudgetWindow    time.Duration // period over which the client budget applies
 }
 
 type SessionManager struct {
 	sem           chan struct{}
 	sessions      map[string]*miner.Builder
 	sessionTimers map[string]*time.Timer
+	recorders     map[string]*sessionRecorder
+	children      map[string][]string      // sessions chained on top of each session
+	invalidated   map[string]struct{}      // chained sessions whose parent changed, until they expire
+	owners        map[string]string        // identity of the client that opened each session
+	locks         map[string]chan struct{} // serializes the calls to each session, see getSession
+	spent         map[string][]spentBudget // resources spent by the sessions gone, by client
+	admins        map[string]struct{}
 	sessionsLock  sync.RWMutex
 	blockchain    *core.BlockChain
 	pool          *txpool.TxPool
+	bundles       *bundlestore.BundleStore
+	private       *privatepool.PrivatePool
 	config        *Config
 }
 
-func NewSessionManager(blockchain *core.BlockChain, pool *txpool.TxPool, config *Config) *SessionManager {
+func NewSessionManager(blockchain *core.BlockChain, pool *txpool.TxPool, bundles *bundlestore.BundleStore, private *privatepool.PrivatePool, config *Config) *SessionManager {
 	if config.GasCeil == 0 {
 		config.GasCeil = 1000000000000000000
 	}
@@ -46,6 +113,9 @@ func NewSessionManager(blockchain *core.BlockChain, pool *txpool.TxPool, config
 	if config.MaxConcurrentSessions <= 0 {
 		config.MaxConcurrentSessions = 16 // chosen arbitrarily
 	}
+	if config.ClientBudgetWindow == 0 {
+		config.ClientBudgetWindow = time.Minute
+	}
 
 	sem := make(chan struct{}, config.MaxConcurrentSessions)
 	for len(sem) < cap(sem) {
@@ -56,9 +126,23 @@ func NewSessionManager(blockchain *core.BlockChain, pool *txpool.TxPool, config
 		sem:           sem,
 		sessions:      make(map[string]*miner.Builder),
 		sessionTimers: make(map[string]*time.Timer),
+		recorders:     make(map[string]*sessionRecorder),
+		children:      make(map[string][]string),
+		invalidated:   make(map[string]struct{}),
+		owners:        make(map[string]string),
+		locks:         make(map[string]chan struct{}),
+		spent:         make(map[string][]spentBudget),
+		admins:        make(map[string]struct{}),
 		blockchain:    blockchain,
 		config:        config,
 		pool:          pool,
+		bundles:       bundles,
+		private:       private,
+	}
+	for _, admin := range config.Admins {
+		if admin != "" {
+			s.admins[admin] = struct{}{}
+		}
 	}
 	return s
 }
@@ -71,83 +155,278 @@ func (s *SessionManager) TxPool() *txpool.TxPool {
 	return s.pool
 }
 
-func (s *SessionManager) newBuilder(args *api.BuildBlockArgs) (*miner.Builder, error) {
-	builderCfg := &miner.BuilderConfig{
+func (s *SessionManager) builderConfig() *miner.BuilderConfig {
+	return &miner.BuilderConfig{
 		ChainConfig: s.blockchain.Config(),
 		Engine:      s.blockchain.Engine(),
 		Chain:       s.blockchain,
 		EthBackend:  s,
 		GasCeil:     s.config.GasCeil,
+		DenyList:    s.config.DenyList,
+	}
+}
+
+func (s *SessionManager) newBuilder(args *api.BuildBlockArgs) (*miner.Builder, error) {
+	session, err := miner.NewBuilder(s.builderConfig(), newBuilderArgs(args))
+	if err != nil {
+		return nil, err
 	}
+	return session, nil
+}
 
-	builderArgs := &miner.BuilderArgs{
+func newBuilderArgs(args *api.BuildBlockArgs) *miner.BuilderArgs {
+	return &miner.BuilderArgs{
 		ParentHash:     args.Parent,
 		FeeRecipient:   args.FeeRecipient,
 		ProposerPubkey: args.ProposerPubkey,
 		Extra:          args.Extra,
 		Slot:           args.Slot,
+		Timestamp:      args.Timestamp,
+		Random:         args.Random,
+		Withdrawals:    args.Withdrawals,
+		BeaconRoot:     args.BeaconRoot,
+		GasLimit:       args.GasLimit,
 	}
-
-	session, err := miner.NewBuilder(builderCfg, builderArgs)
-	if err != nil {
-		return nil, err
-	}
-	return session, nil
 }
 
 // NewSession creates a new builder session and returns the session id
 func (s *SessionManager) NewSession(ctx context.Context, args *api.BuildBlockArgs) (string, error) {
 	if args == nil {
-		return "", fmt.Errorf("args cannot be nil")
+		return "", fmt.Errorf("%w: args cannot be nil", api.ErrInvalidParams)
+	}
+	return s.openSession(ctx, args, func(ctx context.Context) (*miner.Builder, error) {
+		if args.ParentSession != "" {
+			return s.newChildBuilder(ctx, args)
+		}
+		return s.newBuilder(args)
+	})
+}
+
+// NewSessionFromBlock creates a session for the given chain block on top of its
+// parent and re-applies the transactions of the block before opts.TxIndex. The
+// re-applied transactions are recorded as the first call of the session if it
+// is recorded.
+func (s *SessionManager) NewSessionFromBlock(ctx context.Context, blockHash common.Hash, opts *api.NewSessionFromBlockOpts) (string, error) {
+	if opts == nil {
+		opts = &api.NewSessionFromBlockOpts{}
+	}
+	// the transactions re-applied count against the budget of the client
+	if err := s.checkBudget(ctx, ""); err != nil {
+		return "", err
+	}
+	block := s.blockchain.GetBlockByHash(blockHash)
+	if block == nil {
+		return "", fmt.Errorf("%w: block %s not found", api.ErrInvalidParams.WithData(&api.HashErrorData{Hash: blockHash}), blockHash)
+	}
+	builderArgs := miner.BuilderArgsFromBlock(block)
+	args := &api.BuildBlockArgs{
+		Parent:       builderArgs.ParentHash,
+		Timestamp:    builderArgs.Timestamp,
+		FeeRecipient: builderArgs.FeeRecipient,
+		GasLimit:     builderArgs.GasLimit,
+		Random:       builderArgs.Random,
+		Withdrawals:  builderArgs.Withdrawals,
+		BeaconRoot:   builderArgs.BeaconRoot,
+		Extra:        builderArgs.Extra,
+		Record:       opts.Record,
+	}
+	var results []*api.SimulateTransactionResult
+	id, err := s.openSession(ctx, args, func(ctx context.Context) (*miner.Builder, error) {
+		builder, res, err := miner.NewBuilderFromBlock(ctx, s.builderConfig(), block, int(opts.TxIndex))
+		results = res
+		return builder, err
+	})
+	if err != nil {
+		return "", err
+	}
+	s.record(id, "addTransactions", results, nil, block.Transactions()[:opts.TxIndex])
+	return id, nil
+}
+
+// openSession registers the builder created by newBuilder as a new session.
+// The builder is created without holding the sessions lock, so that the calls
+// to the other sessions are not blocked meanwhile.
+func (s *SessionManager) openSession(ctx context.Context, args *api.BuildBlockArgs, newBuilder func(ctx context.Context) (*miner.Builder, error)) (string, error) {
+	ctx, span := telemetry.Start(ctx, "suave.SessionManager.openSession")
+	defer span.End()
+
+	if err := s.checkAuthenticated(ctx); err != nil {
+		return "", err
 	}
 	// Wait for session to become available
 	select {
 	case <-s.sem:
-		s.sessionsLock.Lock()
-		defer s.sessionsLock.Unlock()
 	case <-ctx.Done():
-		return "", ctx.Err()
+		return "", fmt.Errorf("%w: %w", api.ErrTooManySessions.WithData(&api.ResourceErrorData{Resource: "sessions"}), ctx.Err())
 	}
-
-	session, err := s.newBuilder(args)
+	defer func() {
+		// Technically, we are certain that there is an open slot in the semaphore
+		// channel, but let's be defensive and panic if the invariant is violated.
+		select {
+		case s.sem <- struct{}{}:
+		default:
+			panic("released more sessions than are open") // unreachable
+		}
+	}()
+
+	builderCtx, builderSpan := telemetry.Start(ctx, "suave.SessionManager.newBuilder")
+	session, err := newBuilder(builderCtx)
+	telemetry.End(builderSpan, err)
 	if err != nil {
 		return "", err
 	}
 
-	id := uuid.New().String()[:7]
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+
+	if args.ParentSession != "" {
+		// the parent may have expired or been invalidated meanwhile
+		if _, ok := s.sessions[args.ParentSession]; !ok {
+			return "", fmt.Errorf("parent %w", sessionError(api.ErrSessionNotFound, args.ParentSession))
+		}
+		if _, ok := s.invalidated[args.ParentSession]; ok {
+			return "", fmt.Errorf("parent %w", sessionError(ErrSessionInvalidated, args.ParentSession))
+		}
+	}
+
+	id := uuid.New().String()
+	span.SetAttributes(attribute.String("session.id", id))
 	s.sessions[id] = session
+	s.owners[id], _ = s.caller(ctx)
+	s.locks[id] = make(chan struct{}, 1)
+	if args.Record {
+		s.recorders[id] = newSessionRecorder(args, session.ParentHash())
+	}
+	if args.ParentSession != "" {
+		s.children[args.ParentSession] = append(s.children[args.ParentSession], id)
+	}
 
 	// start session timer
 	s.sessionTimers[id] = time.AfterFunc(s.config.SessionIdleTimeout, func() {
 		s.sessionsLock.Lock()
 		defer s.sessionsLock.Unlock()
 
-		delete(s.sessions, id)
-		delete(s.sessionTimers, id)
+		s.removeSession(id)
+		sessionExpireMeter.Mark(1)
 	})
+	sessionOpenMeter.Mark(1)
+	liveSessionsGauge.Update(int64(len(s.sessions)))
 
-	// Technically, we are certain that there is an open slot in the semaphore
-	// channel, but let's be defensive and panic if the invariant is violated.
-	select {
-	case s.sem <- struct{}{}:
-	default:
-		panic("released more sessions than are open") // unreachable
+	return id, nil
+}
+
+// removeSession forgets the session. The resources it spent still count
+// against the budget of its client. The sessions lock must be held.
+func (s *SessionManager) removeSession(id string) {
+	if session, ok := s.sessions[id]; ok {
+		s.addSpent(s.owners[id], session.Usage())
 	}
+	delete(s.sessions, id)
+	delete(s.sessionTimers, id)
+	delete(s.recorders, id)
+	delete(s.children, id)
+	delete(s.invalidated, id)
+	delete(s.owners, id)
+	delete(s.locks, id)
+	liveSessionsGauge.Update(int64(len(s.sessions)))
+}
 
-	return id, nil
+// caller returns the identity of the client of the request, and whether it can
+// access the sessions of every client. The operator of the node, calling
+// in-process or over IPC, and the configured admins can.
+func (s *SessionManager) caller(ctx context.Context) (string, bool) {
+	info := rpc.PeerInfoFromContext(ctx)
+	if info.Transport == "" || info.Transport == "ipc" {
+		return info.Subject, true
+	}
+	_, admin := s.admins[info.Subject]
+	return info.Subject, admin
+}
+
+// checkOwner returns an error if the client of the request did not open the
+// session. The sessions lock must be held.
+func (s *SessionManager) checkOwner(ctx context.Context, sessionId string) error {
+	subject, admin := s.caller(ctx)
+	if !admin && (subject == "" || s.owners[sessionId] != subject) {
+		return sessionError(api.ErrSessionForbidden, sessionId)
+	}
+	return nil
+}
+
+// checkAuthenticated returns an error if the client of the request is anonymous
+// and not the operator. The anonymous clients cannot be told apart, so they
+// cannot own sessions.
+func (s *SessionManager) checkAuthenticated(ctx context.Context) error {
+	if subject, admin := s.caller(ctx); !admin && subject == "" {
+		return api.ErrUnauthenticated
+	}
+	return nil
+}
+
+// sentBy returns whether the client of the request sent the bundle or the
+// private transaction, or can access the ones of every client. The anonymous
+// clients cannot access the ones they sent.
+func (s *SessionManager) sentBy(ctx context.Context, sentBy func(client string) bool) bool {
+	subject, admin := s.caller(ctx)
+	return admin || (subject != "" && sentBy(subject))
 }
 
-func (s *SessionManager) getSession(sessionId string, allowOnTheFlySession bool) (*miner.Builder, error) {
+// getSession returns the builder of the session, or a new builder for an
+// on-the-fly session if sessionId is empty and those are allowed. The builder
+// is not safe for concurrent use, so the calls to a session are serialized: the
+// builder is returned locked, and must be released by calling the returned
+// function once the call is done with it. The time spent waiting for the
+// session is traced.
+func (s *SessionManager) getSession(ctx context.Context, sessionId string, allowOnTheFlySession bool) (*miner.Builder, func(), error) {
 	if sessionId == "" && allowOnTheFlySession {
-		return s.newBuilder(&api.BuildBlockArgs{})
+		_, span := telemetry.Start(ctx, "suave.SessionManager.newBuilder")
+		builder, err := s.newBuilder(&api.BuildBlockArgs{})
+		telemetry.End(span, err)
+		return builder, func() {}, err
 	}
+	_, span := telemetry.Start(ctx, "suave.SessionManager.getSession", attribute.String("session.id", sessionId))
+	defer span.End()
 
 	s.sessionsLock.RLock()
-	defer s.sessionsLock.RUnlock()
+	session, err := s.checkSession(ctx, sessionId)
+	lock := s.locks[sessionId]
+	s.sessionsLock.RUnlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the lock is waited for without holding the sessions lock, so that the
+	// calls to the other sessions are not blocked meanwhile
+	select {
+	case lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("%w: %w", api.ErrBuildInterrupted, context.Cause(ctx))
+	}
+	release := func() { <-lock }
 
+	// the session may have expired or been invalidated meanwhile
+	s.sessionsLock.RLock()
+	_, err = s.checkSession(ctx, sessionId)
+	s.sessionsLock.RUnlock()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return session, release, nil
+}
+
+// checkSession returns the builder of the session if the client of the request
+// can use it, and resets its idle timer. The sessions lock must be held.
+func (s *SessionManager) checkSession(ctx context.Context, sessionId string) (*miner.Builder, error) {
 	session, ok := s.sessions[sessionId]
 	if !ok {
-		return nil, fmt.Errorf("session %s not found", sessionId)
+		return nil, sessionError(api.ErrSessionNotFound, sessionId)
+	}
+	if err := s.checkOwner(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	if _, ok := s.invalidated[sessionId]; ok {
+		return nil, sessionError(ErrSessionInvalidated, sessionId)
 	}
 
 	// reset session timer
@@ -156,53 +435,326 @@ func (s *SessionManager) getSession(sessionId string, allowOnTheFlySession bool)
 	return session, nil
 }
 
-func (s *SessionManager) AddTransaction(sessionId string, tx *types.Transaction) (*api.SimulateTransactionResult, error) {
-	builder, err := s.getSession(sessionId, true)
+// newChildBuilder creates a builder for the block following the last block
+// built by the parent session. The parent is locked meanwhile, so that its
+// state does not change under the child.
+func (s *SessionManager) newChildBuilder(ctx context.Context, args *api.BuildBlockArgs) (*miner.Builder, error) {
+	parent, release, err := s.getSession(ctx, args.ParentSession, false)
 	if err != nil {
+		return nil, fmt.Errorf("parent %w", err)
+	}
+	defer release()
+
+	return parent.NewChild(newBuilderArgs(args))
+}
+
+// updateSession returns the session for a call changing its state. The
+// sessions chained on top of it are invalidated, together with the ones chained
+// on top of them, since their parent block is not the one of the session anymore.
+func (s *SessionManager) updateSession(ctx context.Context, sessionId string, allowOnTheFlySession bool) (*miner.Builder, func(), error) {
+	builder, release, err := s.getSession(ctx, sessionId, allowOnTheFlySession)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, span := telemetry.Start(ctx, "suave.SessionManager.invalidateChildren", attribute.String("session.id", sessionId))
+	defer span.End()
+
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+
+	pending := s.children[sessionId]
+	delete(s.children, sessionId)
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
+		if _, ok := s.sessions[id]; !ok {
+			continue // expired
+		}
+		// the session ends here, it is only kept to report the invalidation
+		// until it expires
+		if _, ok := s.invalidated[id]; !ok {
+			s.invalidated[id] = struct{}{}
+			sessionCloseMeter.Mark(1)
+		}
+		pending = append(pending, s.children[id]...)
+		delete(s.children, id)
+	}
+	return builder, release, nil
+}
+
+func (s *SessionManager) AddTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*api.SimulateTransactionResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
 		return nil, err
 	}
-	return builder.AddTransaction(tx)
+	builder, release, err := s.updateSession(ctx, sessionId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.endOnTheFly(ctx, sessionId, builder)
+	res, err := builder.AddTransaction(ctx, tx)
+	s.record(sessionId, "addTransaction", res, err, tx)
+	return res, err
+}
+
+func (s *SessionManager) AddTransactions(ctx context.Context, sessionId string, txs types.Transactions) ([]*api.SimulateTransactionResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.updateSession(ctx, sessionId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.endOnTheFly(ctx, sessionId, builder)
+	res, err := builder.AddTransactions(ctx, txs)
+	s.record(sessionId, "addTransactions", res, err, txs)
+	return res, err
+}
+
+func (s *SessionManager) AddBundles(ctx context.Context, sessionId string, bundles []*api.Bundle) ([]*api.SimulateBundleResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.updateSession(ctx, sessionId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.endOnTheFly(ctx, sessionId, builder)
+	res, err := builder.AddBundles(ctx, bundles)
+	s.record(sessionId, "addBundles", res, err, bundles)
+	return res, err
+}
+
+func (s *SessionManager) InsertAt(ctx context.Context, sessionId string, index uint64, item api.TxsOrBundle) ([]*api.SessionItemResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.updateSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	res, err := builder.InsertAt(ctx, int(index), item)
+	s.record(sessionId, "insertAt", res, err, index, item)
+	return res, err
+}
+
+func (s *SessionManager) Remove(ctx context.Context, sessionId string, index uint64) ([]*api.SessionItemResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.updateSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	res, err := builder.Remove(ctx, int(index))
+	s.record(sessionId, "remove", res, err, index)
+	return res, err
+}
+
+func (s *SessionManager) SimulateTransaction(ctx context.Context, sessionId string, tx *types.Transaction) (*api.SimulateTransactionResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.getSession(ctx, sessionId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.endOnTheFly(ctx, sessionId, builder)
+	res, err := builder.SimulateTransaction(ctx, tx)
+	s.record(sessionId, "simulateTransaction", res, err, tx)
+	return res, err
+}
+
+func (s *SessionManager) SimulateBundle(ctx context.Context, sessionId string, bundle *api.Bundle) (*api.SimulateBundleResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.getSession(ctx, sessionId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.endOnTheFly(ctx, sessionId, builder)
+	res, err := builder.SimulateBundle(ctx, bundle)
+	s.record(sessionId, "simulateBundle", res, err, bundle)
+	return res, err
+}
+
+func (s *SessionManager) SimulateBundles(ctx context.Context, sessionId string, bundles []*api.Bundle) ([]*api.SimulateBundleResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.getSession(ctx, sessionId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.endOnTheFly(ctx, sessionId, builder)
+	res, err := builder.SimulateBundles(ctx, bundles)
+	s.record(sessionId, "simulateBundles", res, err, bundles)
+	return res, err
 }
 
-func (s *SessionManager) AddTransactions(sessionId string, txs types.Transactions) ([]*api.SimulateTransactionResult, error) {
-	builder, err := s.getSession(sessionId, true)
+func (s *SessionManager) CheckBundleConflict(ctx context.Context, sessionId string, bundleA, bundleB common.Hash) (*api.BundleConflict, error) {
+	builder, release, err := s.getSession(ctx, sessionId, false)
 	if err != nil {
 		return nil, err
 	}
-	return builder.AddTransactions(txs)
+	defer release()
+	res, err := builder.CheckBundleConflict(bundleA, bundleB)
+	s.record(sessionId, "checkBundleConflict", res, err, bundleA, bundleB)
+	return res, err
+}
+
+func (s *SessionManager) MergeBundles(ctx context.Context, sessionId string, bundles []*api.Bundle, strategy *api.MergeStrategy) (*api.MergeBundlesResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.updateSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	res, err := builder.MergeBundles(ctx, bundles, strategy)
+	s.record(sessionId, "mergeBundles", res, err, bundles, strategy)
+	return res, err
+}
+
+func (s *SessionManager) MergeStoredBundles(ctx context.Context, sessionId string, strategy *api.MergeStrategy) (*api.MergeBundlesResult, error) {
+	if s.bundles == nil {
+		return nil, errBundleStoreUnavailable
+	}
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.updateSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	// recorded as a merge of the stored bundles, the store is not part of the dump
+	bundles := s.bundles.Eligible(builder.BlockNumber())
+	res, err := builder.MergeBundles(ctx, bundles, strategy)
+	s.record(sessionId, "mergeBundles", res, err, bundles, strategy)
+	return res, err
+}
+
+// SendBundle adds the bundle to the bundle store. Its status can only be
+// queried by the client that sent it and the admins.
+func (s *SessionManager) SendBundle(ctx context.Context, bundle *api.Bundle) (common.Hash, error) {
+	if s.bundles == nil {
+		return common.Hash{}, errBundleStoreUnavailable
+	}
+	client, _ := s.caller(ctx)
+	hash, err := s.bundles.Add(bundle, client)
+	if err != nil {
+		return common.Hash{}, bundleError(err, bundle.Hash())
+	}
+	return hash, nil
 }
 
-func (s *SessionManager) AddBundles(sessionId string, bundles []*api.Bundle) ([]*api.SimulateBundleResult, error) {
-	builder, err := s.getSession(sessionId, true)
+func (s *SessionManager) GetBundleStatus(ctx context.Context, hash common.Hash) (*api.BundleStatus, error) {
+	if s.bundles == nil {
+		return nil, errBundleStoreUnavailable
+	}
+	if !s.sentBy(ctx, func(client string) bool { return s.bundles.SentBy(hash, client) }) {
+		// the bundles of the other clients are not disclosed
+		return nil, bundleError(fmt.Errorf("%w: %s", bundlestore.ErrBundleNotFound, hash), hash)
+	}
+	status, err := s.bundles.Status(hash)
 	if err != nil {
+		return nil, bundleError(err, hash)
+	}
+	return status, nil
+}
+
+// SendPrivateTransaction adds the transaction to the private pool until the given
+// block number. A nil max block keeps the transaction for the default lifetime.
+// While the transaction is pooled, the public transactions of its sender are
+// rejected by the transaction pool, and a sender with pending public
+// transactions cannot send private ones. The transaction can only be cancelled
+// by the client that sent it and the admins.
+func (s *SessionManager) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlock *big.Int) (common.Hash, error) {
+	if s.private == nil {
+		return common.Hash{}, errPrivatePoolUnavailable
+	}
+	var number uint64
+	if maxBlock != nil {
+		number = maxBlock.Uint64()
+	}
+	client, _ := s.caller(ctx)
+	if err := s.private.AddPrivate(tx, number, client); err != nil {
+		return common.Hash{}, privateTxError(err, tx.Hash())
+	}
+	return tx.Hash(), nil
+}
+
+func (s *SessionManager) CancelPrivateTransaction(ctx context.Context, hash common.Hash) error {
+	if s.private == nil {
+		return errPrivatePoolUnavailable
+	}
+	if !s.sentBy(ctx, func(client string) bool { return s.private.SentBy(hash, client) }) {
+		// the transactions of the other clients are not disclosed
+		return privateTxError(fmt.Errorf("%w: %s", privatepool.ErrTxNotFound, hash), hash)
+	}
+	if err := s.private.Cancel(hash); err != nil {
+		return privateTxError(err, hash)
+	}
+	return nil
+}
+
+func (s *SessionManager) FillPending(ctx context.Context, sessionId string, opts *api.FillPendingOpts) (*api.FillPendingResult, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
 		return nil, err
 	}
-	return builder.AddBundles(bundles)
+	builder, release, err := s.updateSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	start := len(builder.Transactions())
+	res, err := builder.FillPending(ctx, opts)
+	added := append(types.Transactions{}, builder.Transactions()[start:]...)
+	s.recordWithTxs(sessionId, "fillPending", added, res, err, opts)
+	return res, err
 }
 
-func (s *SessionManager) BuildBlock(sessionId string) error {
-	builder, err := s.getSession(sessionId, false)
+func (s *SessionManager) BuildBlock(ctx context.Context, sessionId string) error {
+	builder, release, err := s.updateSession(ctx, sessionId, false)
 	if err != nil {
 		return err
 	}
-	_, err = builder.BuildBlock() // TODO: Return more info
+	defer release()
+	_, err = builder.BuildBlock(ctx) // TODO: Return more info
+	s.record(sessionId, "buildBlock", nil, err)
 	return err
 }
 
-func (s *SessionManager) Bid(sessionId string, blsPubKey phase0.BLSPubKey) (*api.SubmitBlockRequest, error) {
-	builder, err := s.getSession(sessionId, false)
+func (s *SessionManager) Bid(ctx context.Context, sessionId string, blsPubKey phase0.BLSPubKey) (*api.SubmitBlockRequest, error) {
+	builder, release, err := s.getSession(ctx, sessionId, false)
 	if err != nil {
 		return nil, err
 	}
-	return builder.Bid(blsPubKey)
+	defer release()
+	res, err := builder.Bid(blsPubKey)
+	s.record(sessionId, "bid", res, err, blsPubKey)
+	return res, err
 }
 
-func (s *SessionManager) GetBalance(sessionId string, addr common.Address) (*big.Int, error) {
-	builder, err := s.getSession(sessionId, false)
+func (s *SessionManager) GetBalance(ctx context.Context, sessionId string, addr common.Address) (*big.Int, error) {
+	builder, release, err := s.getSession(ctx, sessionId, false)
 	if err != nil {
 		return nil, err
 	}
-	return builder.GetBalance(addr), nil
+	defer release()
+	balance := builder.GetBalance(addr)
+	s.record(sessionId, "getBalance", balance, nil, addr)
+	return balance, nil
 }
 
 // CalcBaseFee calculates the basefee of the header.
@@ -246,12 +798,19 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header) *big.Int {
 	}
 }
 
-func (s *SessionManager) Call(sessionId string, tx_args *ethapi.TransactionArgs) ([]byte, error) {
-	builder, err := s.getSession(sessionId, false)
+// Call executes the call on top of the session. Its gas is capped by the gas
+// left in the budgets of the session and of its client.
+func (s *SessionManager) Call(ctx context.Context, sessionId string, tx_args *ethapi.TransactionArgs) ([]byte, error) {
+	if err := s.checkBudget(ctx, sessionId); err != nil {
+		return nil, err
+	}
+	builder, release, err := s.getSession(ctx, sessionId, false)
 	if err != nil {
 		return nil, err
 	}
-	result, err := builder.Call(tx_args)
+	defer release()
+	result, err := builder.Call(ctx, tx_args, s.callGasCap(sessionId))
+	s.record(sessionId, "call", hexutil.Bytes(result), err, tx_args)
 
 	return result, err
 }
@@ -2,13 +2,20 @@ package builder
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -16,9 +23,17 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/ethereum/go-ethereum/suave/bundlestore"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestSessionManager_SessionTimeout(t *testing.T) {
@@ -33,7 +48,7 @@ func TestSessionManager_SessionTimeout(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 
-	_, err = mngr.getSession(id, false)
+	_, _, err = mngr.getSession(context.Background(), id, false)
 	require.Error(t, err)
 }
 
@@ -89,8 +104,9 @@ func TestSessionManager_SessionRefresh(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		time.Sleep(250 * time.Millisecond)
 
-		_, err = mngr.getSession(id, false)
+		_, release, err := mngr.getSession(context.Background(), id, false)
 		require.NoError(t, err)
+		release()
 	}
 
 	// if we query the session after the idle timeout,
@@ -98,7 +114,7 @@ func TestSessionManager_SessionRefresh(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 
-	_, err = mngr.getSession(id, false)
+	_, _, err = mngr.getSession(context.Background(), id, false)
 	require.Error(t, err)
 }
 
@@ -111,23 +127,597 @@ func TestSessionManager_StartSession(t *testing.T) {
 	require.NoError(t, err)
 
 	txn := bMock.newTransfer(t, common.Address{}, big.NewInt(1))
-	receipt, err := mngr.AddTransaction(id, txn)
+	receipt, err := mngr.AddTransaction(context.Background(), id, txn)
 	require.NoError(t, err)
 	require.NotNil(t, receipt)
 
 	// test that you can simulate the transaction on the fly
-	receipt2, err := mngr.AddTransaction("", txn)
+	receipt2, err := mngr.AddTransaction(context.Background(), "", txn)
 	require.NoError(t, err)
 	require.Equal(t, receipt, receipt2)
 }
 
+func TestSessionManager_Errors(t *testing.T) {
+	mngr, bMock := newSessionManager(t, &Config{})
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("suavex", api.NewServer(mngr)))
+	clt := api.NewClientFromRPC(rpc.DialInProc(srv))
+
+	ctx := context.Background()
+	_, err := clt.AddTransaction(ctx, "unknown", nil)
+	require.ErrorIs(t, err, api.ErrSessionNotFound)
+
+	id, err := clt.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+
+	_, err = clt.Remove(ctx, id, 0)
+	require.ErrorIs(t, err, api.ErrInvalidParams)
+
+	// the core error of a failed transaction is reported with its code
+	txn := bMock.newTransfer(t, common.Address{}, big.NewInt(1))
+	res, err := clt.AddTransaction(ctx, id, txn)
+	require.NoError(t, err)
+	require.NoError(t, res.Err())
+
+	res, err = clt.AddTransaction(ctx, id, txn)
+	require.NoError(t, err)
+	require.Equal(t, api.CodeNonceTooLow, res.ErrorCode)
+	require.ErrorIs(t, res.Err(), api.ErrNonceTooLow)
+
+	bundles, err := clt.AddBundles(ctx, id, []*api.Bundle{{}})
+	require.NoError(t, err)
+	require.ErrorIs(t, bundles[0].Err(), api.ErrInvalidBundle)
+}
+
+func TestSessionManager_Ownership(t *testing.T) {
+	bMock := newTestBackend(t)
+	store := bundlestore.New(bundlestore.DefaultConfig, bMock.chain)
+	defer store.Close()
+	mngr := NewSessionManager(bMock.chain, bMock.pool, store, nil, &Config{Admins: []string{"operator"}})
+
+	// the clients are authenticated by the subject header
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("suavex", api.NewServer(mngr)))
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r.WithContext(rpc.WithAuthSubject(r.Context(), r.Header.Get("X-Subject"))))
+	}))
+	defer httpSrv.Close()
+	newClient := func(subject string) *api.APIClient {
+		clt, err := rpc.DialHTTP(httpSrv.URL)
+		require.NoError(t, err)
+		clt.SetHeader("X-Subject", subject)
+		return api.NewClientFromRPC(clt)
+	}
+	alice, bob, operator, anonymous := newClient("alice"), newClient("bob"), newClient("operator"), newClient("")
+
+	ctx := context.Background()
+	id, err := alice.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	require.Len(t, id, 36)
+
+	// the anonymous clients cannot tell their sessions apart
+	_, err = anonymous.NewSession(ctx, &api.BuildBlockArgs{})
+	require.ErrorIs(t, err, api.ErrUnauthenticated)
+	_, err = anonymous.GetBalance(ctx, id, common.Address{})
+	require.ErrorIs(t, err, api.ErrSessionForbidden)
+
+	txn := bMock.newTransfer(t, common.Address{}, big.NewInt(1))
+	_, err = bob.AddTransaction(ctx, id, txn)
+	require.ErrorIs(t, err, api.ErrSessionForbidden)
+	_, err = bob.ExportSession(ctx, id)
+	require.ErrorIs(t, err, api.ErrSessionForbidden)
+	_, err = bob.NewSession(ctx, &api.BuildBlockArgs{ParentSession: id})
+	require.ErrorIs(t, err, api.ErrSessionForbidden)
+
+	res, err := alice.AddTransaction(ctx, id, txn)
+	require.NoError(t, err)
+	require.True(t, res.Success, res.Error)
+
+	// the admins and the in-process calls access every session
+	_, err = operator.GetBalance(ctx, id, common.Address{})
+	require.NoError(t, err)
+	_, err = mngr.GetBalance(ctx, id, common.Address{})
+	require.NoError(t, err)
+
+	// the status of a bundle is only disclosed to the clients that sent it
+	hash, err := alice.SendBundle(ctx, &api.Bundle{Txs: types.Transactions{txn}})
+	require.NoError(t, err)
+	_, err = alice.GetBundleStatus(ctx, hash)
+	require.NoError(t, err)
+	_, err = operator.GetBundleStatus(ctx, hash)
+	require.NoError(t, err)
+	for _, clt := range []*api.APIClient{bob, anonymous} {
+		_, err = clt.GetBundleStatus(ctx, hash)
+		require.ErrorIs(t, err, api.ErrBundleNotFound)
+	}
+}
+
+func TestSessionManager_Budget(t *testing.T) {
+	mngr, _ := newSessionManager(t, &Config{
+		SessionBudget: api.Budget{Txs: 2},
+		ClientBudget:  api.Budget{Txs: 3},
+	})
+	ctx := context.Background()
+
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	transfer := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0xfe}, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+		require.NoError(t, err)
+		return tx
+	}
+
+	id, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.AddTransactions(ctx, id, types.Transactions{transfer(0), transfer(1)})
+	require.NoError(t, err)
+
+	// the session ran out of transactions, only the calls without simulation
+	// are allowed
+	_, err = mngr.SimulateTransaction(ctx, id, transfer(2))
+	require.ErrorIs(t, err, api.ErrBudgetExceeded)
+	_, err = mngr.AddTransaction(ctx, id, transfer(2))
+	require.ErrorIs(t, err, api.ErrBudgetExceeded)
+	_, err = mngr.GetBalance(ctx, id, common.Address{0xfe})
+	require.NoError(t, err)
+
+	info, err := mngr.GetSessionInfo(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), info.Txs)
+	require.Equal(t, uint64(2), info.Usage.Txs)
+	require.Equal(t, 2*params.TxGas, info.Usage.Gas)
+	require.NotZero(t, info.Usage.StateCopies)
+	require.Equal(t, *info.Usage, *info.ClientUsage)
+	require.Equal(t, uint64(2), info.Budget.Txs)
+
+	// the open sessions of the client share the client budget
+	other, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, other, transfer(0))
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, other, transfer(1))
+	require.ErrorIs(t, err, api.ErrBudgetExceeded)
+	require.ErrorContains(t, err, "client transactions")
+
+	// the resources of the sessions gone still count in the client budget
+	mngr.sessionsLock.Lock()
+	mngr.removeSession(id)
+	mngr.removeSession(other)
+	mngr.sessionsLock.Unlock()
+
+	id, err = mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, id, transfer(0))
+	require.ErrorIs(t, err, api.ErrBudgetExceeded)
+	_, err = mngr.SimulateTransaction(ctx, "", transfer(0))
+	require.ErrorIs(t, err, api.ErrBudgetExceeded)
+}
+
+func TestSessionManager_CallBudget(t *testing.T) {
+	mngr, _ := newSessionManager(t, &Config{
+		SessionBudget: api.Budget{Gas: params.TxGas + 10_000},
+		ClientBudget:  api.Budget{Txs: 1},
+	})
+	ctx := context.Background()
+
+	to := common.Address{0xfe}
+	call := func(id string) error {
+		_, err := mngr.Call(ctx, id, &ethapi.TransactionArgs{From: &testBankAddress, To: &to})
+		return err
+	}
+
+	// the gas of the calls is capped by what is left in the session budget
+	id, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	require.NoError(t, call(id))
+	require.ErrorIs(t, call(id), core.ErrIntrinsicGas)
+
+	info, err := mngr.GetSessionInfo(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, params.TxGas, info.Usage.Gas)
+	require.Zero(t, info.Usage.Txs)
+
+	// the on-the-fly sessions count in the client budget
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	tx, err := types.SignTx(types.NewTransaction(0, to, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+	require.NoError(t, err)
+	_, err = mngr.SimulateTransaction(ctx, "", tx)
+	require.NoError(t, err)
+	_, err = mngr.SimulateTransaction(ctx, "", tx)
+	require.ErrorIs(t, err, api.ErrBudgetExceeded)
+	require.ErrorIs(t, call(id), api.ErrBudgetExceeded)
+}
+
+func TestSessionManager_ConcurrentCalls(t *testing.T) {
+	mngr, backend := newSessionManager(t, &Config{})
+	ctx := context.Background()
+
+	id, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+
+	to := common.Address{0xfe}
+	args := &ethapi.TransactionArgs{From: &testBankAddress, To: &to}
+	tx := backend.newTransfer(t, to, big.NewInt(1))
+	bundle := &api.Bundle{Txs: types.Transactions{tx}}
+
+	// the calls to a session are serialized, they would race on the state of
+	// its builder otherwise
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 3*8)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, err := mngr.Call(ctx, id, args)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := mngr.SimulateTransaction(ctx, id, tx)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := mngr.SimulateBundle(ctx, id, bundle)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// a call waiting for the session gives up once its context is done
+	_, release, err := mngr.getSession(ctx, id, false)
+	require.NoError(t, err)
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = mngr.Call(timeout, id, args)
+	require.ErrorIs(t, err, api.ErrBuildInterrupted)
+	release()
+
+	_, err = mngr.Call(ctx, id, args)
+	require.NoError(t, err)
+}
+
+func TestSessionManager_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	telemetry.SetTracer(telemetry.NewWithProcessor(telemetry.Config{}, recorder))
+	defer telemetry.SetTracer(nil)
+
+	mngr, backend := newSessionManager(t, &Config{})
+	ctx, request := telemetry.Start(context.Background(), "request")
+
+	id, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, id, backend.newTransfer(t, common.Address{}, big.NewInt(1)))
+	require.NoError(t, err)
+	request.End()
+
+	// the builder creation and the wait for the session are traced apart from
+	// the work of the builder
+	parents := make(map[trace.SpanID]string)
+	for _, span := range recorder.Ended() {
+		parents[span.SpanContext().SpanID()] = span.Name()
+	}
+	var names []string
+	for _, span := range recorder.Ended() {
+		if span.Name() != "request" {
+			names = append(names, parents[span.Parent().SpanID()]+" > "+span.Name())
+		}
+	}
+	require.Equal(t, []string{
+		"suave.SessionManager.openSession > suave.SessionManager.newBuilder",
+		"request > suave.SessionManager.openSession",
+		"request > suave.SessionManager.getSession",
+		"request > suave.SessionManager.invalidateChildren",
+		"miner.Builder.AddTransaction > miner.applyTransaction",
+		"request > miner.Builder.AddTransaction",
+	}, names)
+}
+
+func TestSessionManager_BundleStore(t *testing.T) {
+	backend := newTestBackend(t)
+	store := bundlestore.New(bundlestore.DefaultConfig, backend.chain)
+	defer store.Close()
+
+	mngr := NewSessionManager(backend.chain, backend.pool, store, nil, &Config{})
+
+	bundle := &api.Bundle{
+		Txs: types.Transactions{backend.newTransfer(t, common.Address{}, big.NewInt(1))},
+	}
+	hash, err := mngr.SendBundle(context.Background(), bundle)
+	require.NoError(t, err)
+
+	status, err := mngr.GetBundleStatus(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, api.BundleStatusPending, status.Status)
+
+	// the errors of the store are reported with their code and the bundle hash
+	_, err = mngr.SendBundle(context.Background(), &api.Bundle{})
+	require.ErrorIs(t, err, api.ErrInvalidBundle)
+	require.ErrorIs(t, err, bundlestore.ErrEmptyTxs)
+
+	unknown := common.Hash{0x1}
+	_, err = mngr.GetBundleStatus(context.Background(), unknown)
+	require.ErrorIs(t, err, bundlestore.ErrBundleNotFound)
+	rerr, ok := api.ToRPCError(err).(*api.Error)
+	require.True(t, ok)
+	require.Equal(t, api.CodeBundleNotFound, rerr.Code)
+	require.Equal(t, &api.HashErrorData{Hash: unknown}, rerr.Data)
+
+	// without private pool
+	err = mngr.CancelPrivateTransaction(context.Background(), unknown)
+	require.ErrorIs(t, err, api.ErrUnavailable)
+
+	id, err := mngr.NewSession(context.TODO(), &api.BuildBlockArgs{})
+	require.NoError(t, err)
+
+	res, err := mngr.MergeStoredBundles(context.Background(), id, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Landed, 1)
+	require.Equal(t, hash, res.Landed[0].Hash)
+}
+
+func TestSessionManager_ExportImport(t *testing.T) {
+	mngr, bMock := newSessionManager(t, &Config{})
+
+	// the sessions are only recorded on request
+	id, err := mngr.NewSession(context.TODO(), &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.ExportSession(context.Background(), id)
+	require.ErrorIs(t, err, api.ErrInvalidParams)
+
+	id, err = mngr.NewSession(context.TODO(), &api.BuildBlockArgs{Record: true})
+	require.NoError(t, err)
+
+	to := common.Address{0x1}
+	_, err = mngr.AddTransaction(context.Background(), id, bMock.newTransfer(t, to, big.NewInt(1)))
+	require.NoError(t, err)
+	_, err = mngr.GetBalance(context.Background(), id, to)
+	require.NoError(t, err)
+
+	dump, err := mngr.ExportSession(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, bMock.chain.CurrentHeader().Hash(), dump.ParentHash)
+	require.Len(t, dump.Calls, 2)
+
+	res, err := mngr.ImportSession(context.TODO(), dump)
+	require.NoError(t, err)
+	require.NotEqual(t, id, res.SessionId)
+	require.Equal(t, 2, res.Replayed)
+	require.Nil(t, res.Divergence)
+
+	// the replay stops at the first call with a different result
+	dump.Calls[1].Result = json.RawMessage("2")
+
+	res, err = mngr.ImportSession(context.TODO(), dump)
+	require.NoError(t, err)
+	require.NotNil(t, res.Divergence)
+	require.Equal(t, 1, res.Divergence.Index)
+	require.Equal(t, "getBalance", res.Divergence.Method)
+	require.Equal(t, json.RawMessage("1"), res.Divergence.ActualResult)
+}
+
+func TestSessionManager_InsertAtRemove(t *testing.T) {
+	mngr, bMock := newSessionManager(t, &Config{})
+	ctx := context.Background()
+
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	transfer := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0xfe}, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+		require.NoError(t, err)
+		return tx
+	}
+
+	id, err := mngr.NewSession(ctx, &api.BuildBlockArgs{Record: true})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, id, transfer(1))
+	require.NoError(t, err)
+
+	// the transaction only succeeds behind the one with the previous nonce
+	res, err := mngr.InsertAt(ctx, id, 0, api.TxsOrBundle{Bundle: &api.Bundle{Txs: types.Transactions{transfer(0), transfer(1)}}})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.True(t, res[0].Bundle.Success, res[0].Bundle.Error)
+
+	res, err = mngr.InsertAt(ctx, id, 1, api.TxsOrBundle{Txs: types.Transactions{transfer(2)}})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	res, err = mngr.Remove(ctx, id, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.False(t, res[0].Tx.Success)
+
+	balance, err := mngr.GetBalance(ctx, id, common.Address{0xfe})
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+
+	// the changes are replayed from the recording
+	dump, err := mngr.ExportSession(ctx, id)
+	require.NoError(t, err)
+	imported, err := mngr.ImportSession(ctx, dump)
+	require.NoError(t, err)
+	require.Nil(t, imported.Divergence)
+	require.Equal(t, bMock.chain.CurrentHeader().Hash(), dump.ParentHash)
+}
+
+func TestSessionManager_ChainedSessions(t *testing.T) {
+	// clique cannot prepare the headers on top of unsealed blocks
+	bMock := newMergedTestBackend(t)
+	mngr := NewSessionManager(bMock.chain, bMock.pool, nil, nil, &Config{})
+	ctx := context.Background()
+
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	transfer := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0xfe}, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+		require.NoError(t, err)
+		return tx
+	}
+
+	parent, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, parent, transfer(0))
+	require.NoError(t, err)
+
+	// the parent block must be built first
+	_, err = mngr.NewSession(ctx, &api.BuildBlockArgs{ParentSession: parent})
+	require.ErrorIs(t, err, miner.ErrBlockNotBuilt)
+	require.NoError(t, mngr.BuildBlock(ctx, parent))
+
+	child, err := mngr.NewSession(ctx, &api.BuildBlockArgs{ParentSession: parent})
+	require.NoError(t, err)
+	parentBuilder, release, err := mngr.getSession(context.Background(), parent, false)
+	require.NoError(t, err)
+	release()
+	childBuilder, release, err := mngr.getSession(context.Background(), child, false)
+	require.NoError(t, err)
+	release()
+	require.Equal(t, bMock.chain.CurrentHeader().Number.Uint64()+2, childBuilder.BlockNumber().Uint64())
+
+	// the child starts from the post-state of the parent
+	res, err := mngr.AddTransaction(ctx, child, transfer(1))
+	require.NoError(t, err)
+	require.True(t, res.Success, res.Error)
+	balance, err := mngr.GetBalance(ctx, child, common.Address{0xfe})
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2), balance)
+	require.NoError(t, mngr.BuildBlock(ctx, child))
+
+	grandchild, err := mngr.NewSession(ctx, &api.BuildBlockArgs{ParentSession: child})
+	require.NoError(t, err)
+	require.NotEqual(t, parentBuilder.ParentHash(), childBuilder.ParentHash())
+
+	// a change of the parent invalidates the whole chain on top of it
+	_, err = mngr.AddTransaction(ctx, parent, transfer(1))
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, child, transfer(2))
+	require.ErrorIs(t, err, ErrSessionInvalidated)
+	_, err = mngr.GetBalance(ctx, grandchild, common.Address{0xfe})
+	require.ErrorIs(t, err, ErrSessionInvalidated)
+	_, err = mngr.NewSession(ctx, &api.BuildBlockArgs{ParentSession: child})
+	require.ErrorIs(t, err, ErrSessionInvalidated)
+
+	// the parent itself can still be used
+	_, err = mngr.GetBalance(ctx, parent, common.Address{0xfe})
+	require.NoError(t, err)
+}
+
+func TestSessionManager_ImportChainedSession(t *testing.T) {
+	bMock := newMergedTestBackend(t)
+	mngr := NewSessionManager(bMock.chain, bMock.pool, nil, nil, &Config{})
+	ctx := context.Background()
+
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	transfer := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0xfe}, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+		require.NoError(t, err)
+		return tx
+	}
+
+	parent, err := mngr.NewSession(ctx, &api.BuildBlockArgs{})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, parent, transfer(0))
+	require.NoError(t, err)
+	require.NoError(t, mngr.BuildBlock(ctx, parent))
+
+	child, err := mngr.NewSession(ctx, &api.BuildBlockArgs{ParentSession: parent, Record: true})
+	require.NoError(t, err)
+	_, err = mngr.AddTransaction(ctx, child, transfer(1))
+	require.NoError(t, err)
+	_, err = mngr.GetBalance(ctx, child, common.Address{0xfe})
+	require.NoError(t, err)
+
+	dump, err := mngr.ExportSession(ctx, child)
+	require.NoError(t, err)
+	require.Equal(t, parent, dump.Args.ParentSession)
+
+	// the block of the parent session is not known outside of it
+	_, err = mngr.ImportSession(ctx, dump)
+	require.ErrorIs(t, err, api.ErrInvalidParams)
+
+	// the dump is replayed on the block once it is part of the chain, even
+	// after the parent session is gone
+	builder, release, err := mngr.getSession(ctx, parent, false)
+	require.NoError(t, err)
+	block, err := builder.BuildBlock(ctx)
+	release()
+	require.NoError(t, err)
+	require.Equal(t, dump.ParentHash, block.Hash())
+	_, err = bMock.chain.InsertChain(types.Blocks{block})
+	require.NoError(t, err)
+
+	mngr.sessionsLock.Lock()
+	mngr.removeSession(parent)
+	mngr.sessionsLock.Unlock()
+
+	res, err := mngr.ImportSession(ctx, dump)
+	require.NoError(t, err)
+	require.Equal(t, 2, res.Replayed)
+	require.Nil(t, res.Divergence)
+}
+
+func TestSessionManager_NewSessionFromBlock(t *testing.T) {
+	bMock := newMergedTestBackend(t)
+	mngr := NewSessionManager(bMock.chain, bMock.pool, nil, nil, &Config{})
+	ctx := context.Background()
+
+	gasPrice := big.NewInt(10 * params.InitialBaseFee)
+	txs := make(types.Transactions, 3)
+	for i := range txs {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{0xfe}, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testBankKey)
+		require.NoError(t, err)
+		txs[i] = tx
+	}
+	genesis := bMock.chain.Genesis()
+	_, blocks, _ := core.GenerateChainWithGenesis(bMock.genesis, bMock.chain.Engine(), 1, func(i int, gen *core.BlockGen) {
+		gen.SetPoS()
+		for _, tx := range txs {
+			gen.AddTx(tx)
+		}
+	})
+	_, err := bMock.chain.InsertChain(blocks)
+	require.NoError(t, err)
+	block := blocks[0]
+	require.Equal(t, genesis.Hash(), block.ParentHash())
+
+	_, err = mngr.NewSessionFromBlock(ctx, block.Hash(), &api.NewSessionFromBlockOpts{TxIndex: 4})
+	require.ErrorIs(t, err, miner.ErrInvalidTxIndex)
+
+	id, err := mngr.NewSessionFromBlock(ctx, block.Hash(), &api.NewSessionFromBlockOpts{TxIndex: 2, Record: true})
+	require.NoError(t, err)
+	balance, err := mngr.GetBalance(ctx, id, common.Address{0xfe})
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2), balance)
+
+	// the re-applied transactions are part of the recording
+	dump, err := mngr.ExportSession(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Hash(), dump.ParentHash)
+	require.Equal(t, "addTransactions", dump.Calls[0].Method)
+
+	// with the remaining transactions the session builds the historical block
+	res, err := mngr.AddTransaction(ctx, id, txs[2])
+	require.NoError(t, err)
+	require.True(t, res.Success, res.Error)
+	builder, release, err := mngr.getSession(context.Background(), id, false)
+	require.NoError(t, err)
+	defer release()
+	built, err := builder.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), built.Hash())
+}
+
 func newSessionManager(t *testing.T, cfg *Config) (*SessionManager, *testBackend) {
 	backend := newTestBackend(t)
 
 	if cfg == nil {
 		cfg = &Config{}
 	}
-	return NewSessionManager(backend.chain, backend.pool, cfg), backend
+	return NewSessionManager(backend.chain, backend.pool, nil, nil, cfg), backend
 }
 
 var (
@@ -136,8 +726,9 @@ var (
 )
 
 type testBackend struct {
-	chain *core.BlockChain
-	pool  *txpool.TxPool
+	chain   *core.BlockChain
+	pool    *txpool.TxPool
+	genesis *core.Genesis
 }
 
 func (tb *testBackend) newTransfer(t *testing.T, to common.Address, amount *big.Int) *types.Transaction {
@@ -148,10 +739,6 @@ func (tb *testBackend) newTransfer(t *testing.T, to common.Address, amount *big.
 }
 
 func newTestBackend(t *testing.T) *testBackend {
-	// code based on miner 'newTestWorker'
-	testTxPoolConfig := legacypool.DefaultConfig
-	testTxPoolConfig.Journal = ""
-
 	var (
 		db     = rawdb.NewMemoryDatabase()
 		config = *params.AllCliqueProtocolChanges
@@ -169,6 +756,27 @@ func newTestBackend(t *testing.T) *testBackend {
 	engine.Authorize(testBankAddress, func(account accounts.Account, s string, data []byte) ([]byte, error) {
 		return crypto.Sign(crypto.Keccak256(data), testBankKey)
 	})
+	return newTestBackendWithEngine(t, db, gspec, engine)
+}
+
+// newMergedTestBackend creates a backend on a proof-of-stake chain, before
+// Shanghai so that the blocks need no withdrawals.
+func newMergedTestBackend(t *testing.T) *testBackend {
+	config := *params.MergedTestChainConfig
+	config.ShanghaiTime, config.CancunTime = nil, nil
+
+	var gspec = &core.Genesis{
+		Config:     &config,
+		Alloc:      core.GenesisAlloc{testBankAddress: {Balance: big.NewInt(1000000000000000000)}},
+		Difficulty: common.Big0,
+	}
+	return newTestBackendWithEngine(t, rawdb.NewMemoryDatabase(), gspec, beacon.New(ethash.NewFaker()))
+}
+
+func newTestBackendWithEngine(t *testing.T, db ethdb.Database, gspec *core.Genesis, engine consensus.Engine) *testBackend {
+	// code based on miner 'newTestWorker'
+	testTxPoolConfig := legacypool.DefaultConfig
+	testTxPoolConfig.Journal = ""
 
 	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true}, gspec, nil, engine, vm.Config{}, nil, nil)
 	if err != nil {
@@ -177,5 +785,5 @@ func newTestBackend(t *testing.T) *testBackend {
 	pool := legacypool.New(testTxPoolConfig, chain)
 	txpool, _ := txpool.New(testTxPoolConfig.PriceLimit, chain, []txpool.SubPool{pool})
 
-	return &testBackend{chain: chain, pool: txpool}
+	return &testBackend{chain: chain, pool: txpool, genesis: gspec}
 }
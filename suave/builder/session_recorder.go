@@ -0,0 +1,375 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+// maxRecordingSize is the maximum encoded size of the calls recorded for a
+// session. The recording is dropped once it grows past it.
+const maxRecordingSize = 32 * 1024 * 1024
+
+// sessionRecorder records the calls made to a session so that it can be
+// exported and replayed offline.
+type sessionRecorder struct {
+	mu   sync.Mutex
+	dump *api.SessionDump
+	size int // encoded size of the recorded calls
+}
+
+func newSessionRecorder(args *api.BuildBlockArgs, parent common.Hash) *sessionRecorder {
+	cpy := *args
+	return &sessionRecorder{
+		dump: &api.SessionDump{
+			Args:       &cpy,
+			ParentHash: parent,
+			Calls:      []*api.SessionCall{},
+		},
+	}
+}
+
+// newSessionCall encodes a call with its inputs and outcome.
+func newSessionCall(method string, result interface{}, err error, inputs ...interface{}) (*api.SessionCall, error) {
+	call := &api.SessionCall{
+		Method: method,
+		Inputs: make([]json.RawMessage, len(inputs)),
+	}
+	for i, input := range inputs {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+		call.Inputs[i] = data
+	}
+	data, merr := json.Marshal(result)
+	if merr != nil {
+		return nil, merr
+	}
+	call.Result = data
+	if err != nil {
+		call.Error = err.Error()
+	}
+	return call, nil
+}
+
+// add appends the call to the recording, unless the recording would grow
+// past maxRecordingSize.
+func (r *sessionRecorder) add(call *api.SessionCall) bool {
+	size := len(call.Method) + len(call.Result) + len(call.Error)
+	for _, input := range call.Inputs {
+		size += len(input)
+	}
+	for _, tx := range call.Txs {
+		size += int(tx.Size())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size+size > maxRecordingSize {
+		return false
+	}
+	r.size += size
+	r.dump.Calls = append(r.dump.Calls, call)
+	return true
+}
+
+// export returns a copy of the recording.
+func (r *sessionRecorder) export() *api.SessionDump {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dump := *r.dump
+	dump.Calls = append([]*api.SessionCall{}, r.dump.Calls...)
+	return &dump
+}
+
+// record appends a call to the recording of the session. Calls to on-the-fly
+// sessions and to the sessions opened without recording are not recorded.
+func (s *SessionManager) record(sessionId, method string, result interface{}, err error, inputs ...interface{}) {
+	s.recordWithTxs(sessionId, method, nil, result, err, inputs...)
+}
+
+// recordWithTxs records a call together with the pool transactions it added.
+func (s *SessionManager) recordWithTxs(sessionId, method string, txs types.Transactions, result interface{}, err error, inputs ...interface{}) {
+	s.sessionsLock.RLock()
+	recorder, ok := s.recorders[sessionId]
+	s.sessionsLock.RUnlock()
+	if !ok {
+		return
+	}
+
+	call, cerr := newSessionCall(method, result, err, inputs...)
+	if cerr != nil {
+		s.dropRecording(sessionId, recorder, fmt.Sprintf("failed to encode %s call: %v", method, cerr))
+		return
+	}
+	call.Txs = txs
+	if !recorder.add(call) {
+		s.dropRecording(sessionId, recorder, "recording too large")
+	}
+}
+
+// dropRecording stops recording the session. A partial recording could not be
+// replayed, so it is dropped altogether.
+func (s *SessionManager) dropRecording(sessionId string, recorder *sessionRecorder, reason string) {
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+
+	if s.recorders[sessionId] == recorder {
+		delete(s.recorders, sessionId)
+		log.Warn("Dropped session recording", "session", sessionId, "reason", reason)
+	}
+}
+
+// ExportSession returns the arguments of the session and every call made to it
+// with its inputs and results. Only the sessions opened with Record are
+// recorded.
+func (s *SessionManager) ExportSession(ctx context.Context, sessionId string) (*api.SessionDump, error) {
+	// the call in progress, if any, is part of the dump
+	_, release, err := s.getSession(ctx, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	s.sessionsLock.RLock()
+	recorder, ok := s.recorders[sessionId]
+	s.sessionsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: session %s is not recorded", api.ErrInvalidParams, sessionId)
+	}
+	return recorder.export(), nil
+}
+
+// ImportSession replays the calls of a dump into a new session on top of the
+// same parent block. The results are compared call by call and the replay
+// stops at the first divergence. The new session stays open for inspection.
+//
+// The dump of a chained session is replayed on the block built by its parent
+// session rather than on the parent session, which is usually gone by then, so
+// that block must have made it into the chain.
+func (s *SessionManager) ImportSession(ctx context.Context, dump *api.SessionDump) (*api.ImportSessionResult, error) {
+	if dump == nil || dump.Args == nil {
+		return nil, fmt.Errorf("%w: session dump without build arguments", api.ErrInvalidParams)
+	}
+	// every replayed call is checked against the budgets as well
+	if err := s.checkBudget(ctx, ""); err != nil {
+		return nil, err
+	}
+	args := *dump.Args
+	args.Parent = dump.ParentHash
+	if args.ParentSession != "" {
+		if s.blockchain.GetHeaderByHash(dump.ParentHash) == nil {
+			return nil, fmt.Errorf("%w: block %s built by the parent session %s is not in the chain", api.ErrInvalidParams.WithData(&api.HashErrorData{Hash: dump.ParentHash}), dump.ParentHash, args.ParentSession)
+		}
+		args.ParentSession = ""
+	}
+
+	sessionId, err := s.NewSession(ctx, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &api.ImportSessionResult{SessionId: sessionId}
+	for i, call := range dump.Calls {
+		result, err := s.replayCall(ctx, sessionId, call)
+		if errors.Is(err, errReplayInputs) {
+			return nil, fmt.Errorf("call %d (%s): %w", i, call.Method, err)
+		}
+		res.Replayed++
+
+		actual, cerr := newSessionCall(call.Method, result, err)
+		if cerr != nil {
+			return nil, cerr
+		}
+		if !sameJSON(call.Result, actual.Result) || call.Error != actual.Error {
+			res.Divergence = &api.SessionDivergence{
+				Index:          i,
+				Method:         call.Method,
+				ExpectedResult: call.Result,
+				ActualResult:   actual.Result,
+				ExpectedError:  call.Error,
+				ActualError:    actual.Error,
+			}
+			break
+		}
+	}
+	return res, nil
+}
+
+var errReplayInputs = fmt.Errorf("%w: invalid recorded call", api.ErrInvalidParams)
+
+// replayCall runs a recorded call on the given session.
+func (s *SessionManager) replayCall(ctx context.Context, sessionId string, call *api.SessionCall) (interface{}, error) {
+	decode := func(inputs ...interface{}) error {
+		if len(inputs) != len(call.Inputs) {
+			return fmt.Errorf("%w: expected %d inputs, got %d", errReplayInputs, len(inputs), len(call.Inputs))
+		}
+		for i, input := range inputs {
+			if err := json.Unmarshal(call.Inputs[i], input); err != nil {
+				return fmt.Errorf("%w: input %d: %v", errReplayInputs, i, err)
+			}
+		}
+		return nil
+	}
+
+	switch call.Method {
+	case "addTransaction":
+		var tx *types.Transaction
+		if err := decode(&tx); err != nil {
+			return nil, err
+		}
+		return s.AddTransaction(ctx, sessionId, tx)
+
+	case "addTransactions":
+		var txs types.Transactions
+		if err := decode(&txs); err != nil {
+			return nil, err
+		}
+		return s.AddTransactions(ctx, sessionId, txs)
+
+	case "addBundles":
+		var bundles []*api.Bundle
+		if err := decode(&bundles); err != nil {
+			return nil, err
+		}
+		return s.AddBundles(ctx, sessionId, bundles)
+
+	case "insertAt":
+		var (
+			index uint64
+			item  api.TxsOrBundle
+		)
+		if err := decode(&index, &item); err != nil {
+			return nil, err
+		}
+		return s.InsertAt(ctx, sessionId, index, item)
+
+	case "remove":
+		var index uint64
+		if err := decode(&index); err != nil {
+			return nil, err
+		}
+		return s.Remove(ctx, sessionId, index)
+
+	case "simulateTransaction":
+		var tx *types.Transaction
+		if err := decode(&tx); err != nil {
+			return nil, err
+		}
+		return s.SimulateTransaction(ctx, sessionId, tx)
+
+	case "simulateBundle":
+		var bundle *api.Bundle
+		if err := decode(&bundle); err != nil {
+			return nil, err
+		}
+		return s.SimulateBundle(ctx, sessionId, bundle)
+
+	case "simulateBundles":
+		var bundles []*api.Bundle
+		if err := decode(&bundles); err != nil {
+			return nil, err
+		}
+		return s.SimulateBundles(ctx, sessionId, bundles)
+
+	case "checkBundleConflict":
+		var bundleA, bundleB common.Hash
+		if err := decode(&bundleA, &bundleB); err != nil {
+			return nil, err
+		}
+		return s.CheckBundleConflict(ctx, sessionId, bundleA, bundleB)
+
+	case "mergeBundles":
+		var (
+			bundles  []*api.Bundle
+			strategy *api.MergeStrategy
+		)
+		if err := decode(&bundles, &strategy); err != nil {
+			return nil, err
+		}
+		return s.MergeBundles(ctx, sessionId, bundles, strategy)
+
+	case "fillPending":
+		// the pool content is not part of the dump, the recorded pool
+		// transactions are applied instead
+		var opts *api.FillPendingOpts
+		if err := decode(&opts); err != nil {
+			return nil, err
+		}
+		if call.Error != "" {
+			// failures are not caused by the pool content
+			return s.FillPending(ctx, sessionId, opts)
+		}
+		var recorded api.FillPendingResult
+		if err := json.Unmarshal(call.Result, &recorded); err != nil {
+			return nil, fmt.Errorf("%w: %v", errReplayInputs, err)
+		}
+		results, err := s.AddTransactions(ctx, sessionId, call.Txs)
+		if err != nil {
+			return nil, err
+		}
+		res := &api.FillPendingResult{Txs: []common.Hash{}, DeadlineExceeded: recorded.DeadlineExceeded}
+		for i, result := range results {
+			if !result.Success {
+				return nil, fmt.Errorf("pool transaction %s failed: %s", call.Txs[i].Hash(), result.Error)
+			}
+			res.Txs = append(res.Txs, call.Txs[i].Hash())
+			res.GasUsed += result.Egp
+		}
+		return res, nil
+
+	case "buildBlock":
+		if err := decode(); err != nil {
+			return nil, err
+		}
+		return nil, s.BuildBlock(ctx, sessionId)
+
+	case "bid":
+		var blsPubKey phase0.BLSPubKey
+		if err := decode(&blsPubKey); err != nil {
+			return nil, err
+		}
+		return s.Bid(ctx, sessionId, blsPubKey)
+
+	case "getBalance":
+		var addr common.Address
+		if err := decode(&addr); err != nil {
+			return nil, err
+		}
+		return s.GetBalance(ctx, sessionId, addr)
+
+	case "call":
+		var args *ethapi.TransactionArgs
+		if err := decode(&args); err != nil {
+			return nil, err
+		}
+		res, err := s.Call(ctx, sessionId, args)
+		return hexutil.Bytes(res), err
+	}
+	return nil, fmt.Errorf("%w: unknown method %q", errReplayInputs, call.Method)
+}
+
+// sameJSON compares two JSON documents ignoring insignificant whitespace.
+func sameJSON(a, b json.RawMessage) bool {
+	var bufA, bufB bytes.Buffer
+	if err := json.Compact(&bufA, a); err != nil {
+		return false
+	}
+	if err := json.Compact(&bufB, b); err != nil {
+		return false
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
@@ -0,0 +1,363 @@
+// Package bundlestore keeps the bundles sent to the node across blocks until
+// they are either included on-chain or their inclusion range is over.
+package bundlestore
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/suave/builder/api"
+)
+
+var (
+	ErrStoreFull             = errors.New("bundle store is full")
+	ErrEmptyTxs              = errors.New("empty transactions")
+	ErrInvalidInclusionRange = errors.New("invalid inclusion range")
+	ErrBundleExpired         = errors.New("bundle inclusion range is over")
+	ErrBundleNotFound        = errors.New("bundle not found")
+)
+
+type Config struct {
+	MaxBundles      int    // Maximum number of pending bundles
+	DefaultLifetime uint64 // Number of blocks a bundle without inclusion range is kept
+	StatusCacheSize int    // Number of finalized bundle statuses kept for queries
+}
+
+var DefaultConfig = Config{
+	MaxBundles:      10000,
+	DefaultLifetime: 25,
+	StatusCacheSize: 10000,
+}
+
+// maxReorgDepth is the number of blocks walked back from a new head to find
+// the blocks added since the previous one. The bundles included in the blocks
+// this deep below the head are not expected to be reorged out anymore.
+const maxReorgDepth = 64
+
+// blockChain is the subset of the blockchain used by the store.
+type blockChain interface {
+	CurrentHeader() *types.Header
+	GetHeader(hash common.Hash, number uint64) *types.Header
+	GetBlock(hash common.Hash, number uint64) *types.Block
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+type storedBundle struct {
+	bundle   *api.Bundle
+	clients  []string // clients that sent the bundle
+	seq      uint64   // order of arrival
+	minBlock uint64
+	maxBlock uint64
+}
+
+// eligible returns whether the bundle can be included in the given block
+func (s *storedBundle) eligible(number uint64) bool {
+	return s.minBlock <= number && number <= s.maxBlock
+}
+
+// landedBundle is a bundle that landed on-chain, kept until its block is too
+// deep to be reorged out.
+type landedBundle struct {
+	*storedBundle
+	block  common.Hash
+	number uint64
+}
+
+// finishedBundle is the final status of a bundle, kept for the queries of the
+// clients that sent it.
+type finishedBundle struct {
+	status  *api.BundleStatus
+	clients []string
+}
+
+// BundleStore keeps the bundles until their inclusion range is over or
+// they are included on-chain.
+type BundleStore struct {
+	config Config
+	chain  blockChain
+
+	mu       sync.RWMutex
+	head     *types.Header
+	seq      uint64
+	pending  map[common.Hash]*storedBundle
+	landed   map[common.Hash]*landedBundle
+	finished *lru.Cache[common.Hash, *finishedBundle]
+
+	headSub event.Subscription
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates a bundle store that tracks the head of the given chain.
+func New(config Config, chain blockChain) *BundleStore {
+	if config.MaxBundles <= 0 {
+		config.MaxBundles = DefaultConfig.MaxBundles
+	}
+	if config.DefaultLifetime == 0 {
+		config.DefaultLifetime = DefaultConfig.DefaultLifetime
+	}
+	if config.StatusCacheSize <= 0 {
+		config.StatusCacheSize = DefaultConfig.StatusCacheSize
+	}
+
+	s := &BundleStore{
+		config:   config,
+		chain:    chain,
+		head:     chain.CurrentHeader(),
+		pending:  make(map[common.Hash]*storedBundle),
+		landed:   make(map[common.Hash]*landedBundle),
+		finished: lru.NewCache[common.Hash, *finishedBundle](config.StatusCacheSize),
+		quit:     make(chan struct{}),
+	}
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	s.headSub = chain.SubscribeChainHeadEvent(heads)
+
+	s.wg.Add(1)
+	go s.loop(heads)
+
+	return s
+}
+
+func (s *BundleStore) loop(heads chan core.ChainHeadEvent) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case ev := <-heads:
+			s.reset(ev.Block.Header())
+		case <-s.headSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// Close stops tracking the chain head.
+func (s *BundleStore) Close() {
+	s.headSub.Unsubscribe()
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// reset evicts the bundles included in the blocks added to the chain since
+// the previous head and the ones that cannot be included in the next block
+// anymore. The bundles included in the blocks removed by a reorg are pending
+// again.
+func (s *BundleStore) reset(head *types.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added, removed := s.chainUpdate(s.head, head)
+	s.head = head
+
+	for hash, lb := range s.landed {
+		if _, ok := removed[lb.block]; ok {
+			delete(s.landed, hash)
+			s.finished.Remove(hash)
+			s.pending[hash] = lb.storedBundle
+		} else if lb.number+maxReorgDepth < head.Number.Uint64() {
+			delete(s.landed, hash)
+		}
+	}
+	for _, block := range added {
+		s.evictIncluded(block)
+	}
+	for hash, sb := range s.pending {
+		if sb.maxBlock <= head.Number.Uint64() {
+			s.finish(hash, api.BundleStatusExpired, nil)
+		}
+	}
+}
+
+// chainUpdate returns the blocks added to the chain from the old head to the
+// new one in ascending order, and the hashes of the blocks removed from it by
+// a reorg. At most maxReorgDepth blocks are walked back from each head.
+func (s *BundleStore) chainUpdate(oldHead, newHead *types.Header) ([]*types.Block, map[common.Hash]struct{}) {
+	var (
+		added   []*types.Block
+		removed = make(map[common.Hash]struct{})
+		add     = s.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64())
+		rem     = oldHead
+	)
+	for depth := 0; add != nil && rem != nil && add.Hash() != rem.Hash(); depth++ {
+		if depth == maxReorgDepth {
+			log.Warn("Skipping deep chain update in bundle store", "old", oldHead.Number, "new", newHead.Number)
+			break
+		}
+		// walk back the highest of the two branches, or both of them
+		number := add.NumberU64()
+		if number >= rem.Number.Uint64() {
+			added = append(added, add)
+			add = s.chain.GetBlock(add.ParentHash(), number-1)
+		}
+		if rem.Number.Uint64() >= number {
+			removed[rem.Hash()] = struct{}{}
+			rem = s.chain.GetHeader(rem.ParentHash, rem.Number.Uint64()-1)
+		}
+	}
+	slices.Reverse(added)
+	return added, removed
+}
+
+// evictIncluded evicts the bundles included in the block.
+func (s *BundleStore) evictIncluded(block *types.Block) {
+	included := make(map[common.Hash]struct{}, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		included[tx.Hash()] = struct{}{}
+	}
+
+	for hash, sb := range s.pending {
+		var found int
+		for _, tx := range sb.bundle.Txs {
+			if _, ok := included[tx.Hash()]; ok {
+				found++
+			}
+		}
+		if found == 0 {
+			continue
+		}
+		if found == len(sb.bundle.Txs) {
+			s.finish(hash, api.BundleStatusIncluded, block.Number())
+		} else {
+			// part of the bundle landed on-chain, the rest of it cannot be
+			// applied with the same semantics anymore
+			s.finish(hash, api.BundleStatusInvalid, block.Number())
+		}
+		s.landed[hash] = &landedBundle{storedBundle: sb, block: block.Hash(), number: block.NumberU64()}
+	}
+}
+
+func (s *BundleStore) finish(hash common.Hash, status string, number *big.Int) {
+	clients := s.pending[hash].clients
+	delete(s.pending, hash)
+	s.finished.Add(hash, &finishedBundle{
+		status: &api.BundleStatus{
+			Hash:        hash,
+			Status:      status,
+			BlockNumber: number,
+		},
+		clients: clients,
+	})
+	log.Debug("Bundle evicted from store", "hash", hash, "status", status)
+}
+
+// Add stores the bundle sent by the given client until it is included or its
+// inclusion range is over and returns its hash.
+func (s *BundleStore) Add(bundle *api.Bundle, client string) (common.Hash, error) {
+	if len(bundle.Txs) == 0 {
+		return common.Hash{}, ErrEmptyTxs
+	}
+	if bundle.BlockNumber != nil && bundle.MaxBlock != nil && bundle.BlockNumber.Cmp(bundle.MaxBlock) > 0 {
+		return common.Hash{}, ErrInvalidInclusionRange
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head := s.head.Number.Uint64()
+
+	// the inclusion range follows the semantics of the builder, a bundle with
+	// a block number and no max block can only be included in that block
+	sb := &storedBundle{
+		bundle:   bundle,
+		clients:  []string{client},
+		seq:      s.seq,
+		minBlock: head + 1,
+		maxBlock: head + s.config.DefaultLifetime,
+	}
+	if bundle.BlockNumber != nil {
+		sb.minBlock = bundle.BlockNumber.Uint64()
+		sb.maxBlock = sb.minBlock
+	}
+	if bundle.MaxBlock != nil {
+		sb.maxBlock = bundle.MaxBlock.Uint64()
+	}
+	if sb.maxBlock <= head {
+		return common.Hash{}, ErrBundleExpired
+	}
+
+	hash := bundle.Hash()
+	if prev, ok := s.pending[hash]; ok {
+		if !slices.Contains(prev.clients, client) {
+			prev.clients = append(prev.clients, client)
+		}
+		return hash, nil
+	}
+	if len(s.pending) >= s.config.MaxBundles {
+		return common.Hash{}, ErrStoreFull
+	}
+	s.pending[hash] = sb
+	delete(s.landed, hash)
+	s.finished.Remove(hash)
+	s.seq++
+
+	return hash, nil
+}
+
+// Eligible returns the stored bundles that can be included in the given block
+// in their order of arrival.
+func (s *BundleStore) Eligible(number *big.Int) []*api.Bundle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var eligible []*storedBundle
+	for _, sb := range s.pending {
+		if sb.eligible(number.Uint64()) {
+			eligible = append(eligible, sb)
+		}
+	}
+	sort.Slice(eligible, func(i, j int) bool {
+		return eligible[i].seq < eligible[j].seq
+	})
+
+	bundles := make([]*api.Bundle, len(eligible))
+	for i, sb := range eligible {
+		bundles[i] = sb.bundle
+	}
+	return bundles
+}
+
+// Status returns the status of a bundle sent to the store.
+func (s *BundleStore) Status(hash common.Hash) (*api.BundleStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sb, ok := s.pending[hash]; ok {
+		return &api.BundleStatus{
+			Hash:     hash,
+			Status:   api.BundleStatusPending,
+			MinBlock: new(big.Int).SetUint64(sb.minBlock),
+			MaxBlock: new(big.Int).SetUint64(sb.maxBlock),
+		}, nil
+	}
+	if fb, ok := s.finished.Get(hash); ok {
+		return fb.status, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrBundleNotFound, hash)
+}
+
+// SentBy returns whether the bundle was sent to the store by the given client.
+func (s *BundleStore) SentBy(hash common.Hash, client string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sb, ok := s.pending[hash]; ok {
+		return slices.Contains(sb.clients, client)
+	}
+	if fb, ok := s.finished.Peek(hash); ok {
+		return slices.Contains(fb.clients, client)
+	}
+	return false
+}
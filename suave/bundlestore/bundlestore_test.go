@@ -0,0 +1,206 @@
+package bundlestore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleStore_Eligible(t *testing.T) {
+	store := newTestStore(t, 10)
+
+	exact := newTestBundle(0, big.NewInt(12), nil)
+	ranged := newTestBundle(1, big.NewInt(11), big.NewInt(13))
+	open := newTestBundle(2, nil, nil)
+
+	for _, bundle := range []*api.Bundle{exact, ranged, open} {
+		_, err := store.Add(bundle, "")
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, []*api.Bundle{ranged, open}, store.Eligible(big.NewInt(11)))
+	require.Equal(t, []*api.Bundle{exact, ranged, open}, store.Eligible(big.NewInt(12)))
+	require.Equal(t, []*api.Bundle{open}, store.Eligible(big.NewInt(14)))
+	require.Empty(t, store.Eligible(new(big.Int).SetUint64(10+DefaultConfig.DefaultLifetime+1)))
+}
+
+func TestBundleStore_InvalidBundles(t *testing.T) {
+	store := newTestStore(t, 10)
+
+	_, err := store.Add(&api.Bundle{}, "")
+	require.ErrorIs(t, err, ErrEmptyTxs)
+
+	_, err = store.Add(newTestBundle(0, big.NewInt(12), big.NewInt(11)), "")
+	require.ErrorIs(t, err, ErrInvalidInclusionRange)
+
+	_, err = store.Add(newTestBundle(0, big.NewInt(5), big.NewInt(10)), "")
+	require.ErrorIs(t, err, ErrBundleExpired)
+
+	_, err = store.Status(common.Hash{})
+	require.ErrorIs(t, err, ErrBundleNotFound)
+}
+
+func TestBundleStore_Eviction(t *testing.T) {
+	store, chain := newTestStoreWithChain(t, 10)
+
+	included := newTestBundle(0, nil, nil)
+	partial := &api.Bundle{Txs: types.Transactions{newTestTx(1), newTestTx(2)}}
+	expired := newTestBundle(3, big.NewInt(11), nil)
+
+	for _, bundle := range []*api.Bundle{included, partial, expired} {
+		_, err := store.Add(bundle, "")
+		require.NoError(t, err)
+	}
+
+	status, err := store.Status(included.Hash())
+	require.NoError(t, err)
+	require.Equal(t, api.BundleStatusPending, status.Status)
+
+	store.reset(chain.newBlock(chain.head, 0, newTestTx(0), newTestTx(1)).Header())
+
+	status, err = store.Status(included.Hash())
+	require.NoError(t, err)
+	require.Equal(t, api.BundleStatusIncluded, status.Status)
+	require.Equal(t, big.NewInt(11), status.BlockNumber)
+
+	status, err = store.Status(partial.Hash())
+	require.NoError(t, err)
+	require.Equal(t, api.BundleStatusInvalid, status.Status)
+
+	status, err = store.Status(expired.Hash())
+	require.NoError(t, err)
+	require.Equal(t, api.BundleStatusExpired, status.Status)
+
+	require.Empty(t, store.Eligible(big.NewInt(12)))
+}
+
+func TestBundleStore_SentBy(t *testing.T) {
+	store, chain := newTestStoreWithChain(t, 10)
+
+	bundle := newTestBundle(0, nil, nil)
+	for _, client := range []string{"alice", "bob", "alice"} {
+		_, err := store.Add(bundle, client)
+		require.NoError(t, err)
+	}
+	require.True(t, store.SentBy(bundle.Hash(), "alice"))
+	require.True(t, store.SentBy(bundle.Hash(), "bob"))
+	require.False(t, store.SentBy(bundle.Hash(), "carol"))
+	require.False(t, store.SentBy(common.Hash{}, "alice"))
+
+	// the clients are kept with the final status
+	store.reset(chain.newBlock(chain.head, 0, newTestTx(0)).Header())
+	require.True(t, store.SentBy(bundle.Hash(), "bob"))
+	require.False(t, store.SentBy(bundle.Hash(), "carol"))
+}
+
+func TestBundleStore_ChainUpdate(t *testing.T) {
+	store, chain := newTestStoreWithChain(t, 10)
+	genesis := chain.head
+
+	first := newTestBundle(0, nil, nil)
+	second := newTestBundle(1, nil, nil)
+	for _, bundle := range []*api.Bundle{first, second} {
+		_, err := store.Add(bundle, "")
+		require.NoError(t, err)
+	}
+	requireStatus := func(bundle *api.Bundle, status string, number *big.Int) {
+		t.Helper()
+		res, err := store.Status(bundle.Hash())
+		require.NoError(t, err)
+		require.Equal(t, status, res.Status)
+		require.Equal(t, number, res.BlockNumber)
+	}
+
+	// the blocks inserted at once are reported by a single head event
+	b11 := chain.newBlock(genesis, 0, newTestTx(0))
+	b12 := chain.newBlock(b11.Header(), 0)
+	b13 := chain.newBlock(b12.Header(), 0, newTestTx(1))
+	store.reset(b13.Header())
+
+	requireStatus(first, api.BundleStatusIncluded, big.NewInt(11))
+	requireStatus(second, api.BundleStatusIncluded, big.NewInt(13))
+
+	// a reorg to a shorter branch moves the head back and the bundles of the
+	// removed blocks are pending again
+	side11 := chain.newBlock(genesis, 1)
+	side12 := chain.newBlock(side11.Header(), 1, newTestTx(1))
+	store.reset(side12.Header())
+
+	requireStatus(first, api.BundleStatusPending, nil)
+	requireStatus(second, api.BundleStatusIncluded, big.NewInt(12))
+	require.Equal(t, uint64(12), store.head.Number.Uint64())
+	require.Equal(t, []*api.Bundle{first}, store.Eligible(big.NewInt(13)))
+}
+
+func newTestStore(t *testing.T, head uint64) *BundleStore {
+	store, _ := newTestStoreWithChain(t, head)
+	return store
+}
+
+func newTestStoreWithChain(t *testing.T, head uint64) (*BundleStore, *testChain) {
+	chain := &testChain{blocks: make(map[common.Hash]*types.Block)}
+	chain.head = chain.newBlock(&types.Header{Number: new(big.Int).SetUint64(head - 1)}, 0).Header()
+	store := New(DefaultConfig, chain)
+	t.Cleanup(store.Close)
+	return store, chain
+}
+
+func newTestTx(nonce uint64) *types.Transaction {
+	return types.NewTransaction(nonce, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+}
+
+func newTestBundle(nonce uint64, blockNumber, maxBlock *big.Int) *api.Bundle {
+	return &api.Bundle{
+		Txs:         types.Transactions{newTestTx(nonce)},
+		BlockNumber: blockNumber,
+		MaxBlock:    maxBlock,
+	}
+}
+
+type testChain struct {
+	head   *types.Header
+	blocks map[common.Hash]*types.Block
+	feed   event.Feed
+}
+
+// newBlock adds a block on top of the given parent, the fork tells apart the
+// blocks of different branches.
+func (c *testChain) newBlock(parent *types.Header, fork uint64, txs ...*types.Transaction) *types.Block {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       fork,
+	}
+	block := types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+	c.blocks[block.Hash()] = block
+	return block
+}
+
+func (c *testChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if block := c.GetBlock(hash, number); block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+func (c *testChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block, ok := c.blocks[hash]; ok && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
+
+func (c *testChain) CurrentHeader() *types.Header {
+	return c.head
+}
+
+func (c *testChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
@@ -0,0 +1,164 @@
+package e2e
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+func startNode(t *testing.T) (*node.Node, *eth.Ethereum, *catalyst.SimulatedBeacon) {
+	t.Helper()
+
+	n, err := node.New(&node.Config{
+		P2P: p2p.Config{
+			ListenAddr:  "127.0.0.1:0",
+			NoDiscovery: true,
+			MaxPeers:    0,
+		},
+	})
+	require.NoError(t, err)
+
+	genesis := core.DeveloperGenesisBlock(30_000_000, &testAddr)
+	ethcfg := &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256, Miner: miner.DefaultConfig}
+	ethservice, err := eth.New(n, ethcfg)
+	require.NoError(t, err)
+
+	beacon, err := catalyst.NewSimulatedBeacon(0, ethservice)
+	require.NoError(t, err)
+	n.RegisterLifecycle(beacon)
+
+	require.NoError(t, n.Start())
+	t.Cleanup(func() { n.Close() })
+
+	ethservice.SetSynced()
+	return n, ethservice, beacon
+}
+
+func TestSessionBidInclusion(t *testing.T) {
+	n, ethservice, beacon := startNode(t)
+
+	rpcClient := n.Attach()
+	client := api.NewClientFromRPC(rpcClient)
+
+	relay := NewRelay()
+	srv := httptest.NewServer(relay)
+	defer srv.Close()
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	var builderPubkey phase0.BLSPubKey
+	copy(builderPubkey[:], bls.PublicKeyToBytes(pk))
+
+	signer := types.LatestSigner(ethservice.BlockChain().Config())
+	tx := types.MustSignNewTx(testKey, signer, &types.DynamicFeeTx{
+		ChainID:   ethservice.BlockChain().Config().ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(10 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{0x1},
+		Value:     big.NewInt(1),
+	})
+
+	// the builder sends its block for every slot
+	var bidErr error
+	build := func(slot *Slot) {
+		bidErr = func() error {
+			ctx := context.Background()
+			id, err := client.NewSession(ctx, &api.BuildBlockArgs{
+				Slot:         slot.Number,
+				Parent:       slot.Parent,
+				Timestamp:    slot.Attributes.Timestamp,
+				FeeRecipient: slot.Attributes.SuggestedFeeRecipient,
+				Random:       slot.Attributes.Random,
+				Withdrawals:  slot.Attributes.Withdrawals,
+				BeaconRoot:   slot.Attributes.BeaconRoot,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := client.AddTransaction(ctx, id, tx); err != nil {
+				return err
+			}
+			if err := client.BuildBlock(ctx, id); err != nil {
+				return err
+			}
+			bid, err := client.Bid(ctx, id, builderPubkey)
+			if err != nil {
+				return err
+			}
+			req, err := SignBid(bid, sk)
+			if err != nil {
+				return err
+			}
+			return SubmitBid(ctx, srv.URL, req)
+		}()
+	}
+	proposer := NewProposer(beacon, ethservice.BlockChain(), relay, build)
+
+	hash, err := proposer.Propose()
+	require.NoError(t, err)
+	require.NoError(t, bidErr)
+
+	// the sealed block is the one of the bid, the local pool is empty
+	block := ethservice.BlockChain().GetBlockByHash(hash)
+	require.NotNil(t, block)
+	require.Len(t, block.Transactions(), 1)
+	require.Equal(t, tx.Hash(), block.Transactions()[0].Hash())
+
+	bid := relay.BestBid(block.NumberU64())
+	require.NotNil(t, bid)
+	require.Equal(t, hash, common.Hash(bid.Message.BlockHash))
+}
+
+func TestRelayRejectsInvalidSignature(t *testing.T) {
+	n, ethservice, _ := startNode(t)
+	client := api.NewClientFromRPC(n.Attach())
+
+	ctx := context.Background()
+	id, err := client.NewSession(ctx, &api.BuildBlockArgs{Parent: ethservice.BlockChain().CurrentBlock().Hash()})
+	require.NoError(t, err)
+	require.NoError(t, client.BuildBlock(ctx, id))
+
+	_, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	var builderPubkey phase0.BLSPubKey
+	copy(builderPubkey[:], bls.PublicKeyToBytes(pk))
+
+	bid, err := client.Bid(ctx, id, builderPubkey)
+	require.NoError(t, err)
+
+	// signed with another key than the one in the bid
+	other, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	req, err := SignBid(bid, other)
+	require.NoError(t, err)
+
+	relay := NewRelay()
+	require.ErrorIs(t, relay.SubmitBlock(req), ErrInvalidSignature)
+	require.Nil(t, relay.BestBid(bid.Message.Slot))
+}
@@ -0,0 +1,139 @@
+package e2e
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Slot describes a block to propose, as announced to the builders. The slot
+// number is the number of the block.
+type Slot struct {
+	Number     uint64
+	Parent     common.Hash
+	Attributes *engine.PayloadAttributes
+}
+
+// Proposer seals on a simulated beacon chain the most valuable payload
+// submitted to the relay for every slot, instead of the payload built by the
+// local node. The local payload is sealed if no valid bid was received.
+type Proposer struct {
+	beacon *catalyst.SimulatedBeacon
+	chain  *core.BlockChain
+	relay  *Relay
+	onSlot func(*Slot)
+}
+
+// NewProposer creates a proposer sealing the blocks of the given beacon. The
+// onSlot callback is invoked before every slot is sealed, it lets the builders
+// submit their bids for the slot to the relay. The beacon must not be started.
+func NewProposer(beacon *catalyst.SimulatedBeacon, chain *core.BlockChain, relay *Relay, onSlot func(*Slot)) *Proposer {
+	p := &Proposer{
+		beacon: beacon,
+		chain:  chain,
+		relay:  relay,
+		onSlot: onSlot,
+	}
+	beacon.SetPayloadSource(p.payload)
+	return p
+}
+
+// Propose seals the block of the next slot and returns its hash.
+func (p *Proposer) Propose() (common.Hash, error) {
+	head := p.chain.CurrentBlock().Hash()
+	if hash := p.beacon.Commit(); hash != head {
+		return hash, nil
+	}
+	return common.Hash{}, errors.New("failed to seal the block")
+}
+
+// payload returns the payload of the best bid for the slot.
+func (p *Proposer) payload(parent common.Hash, attrs *engine.PayloadAttributes) *engine.ExecutableData {
+	header := p.chain.GetHeaderByHash(parent)
+	if header == nil {
+		return nil
+	}
+	slot := &Slot{
+		Number:     header.Number.Uint64() + 1,
+		Parent:     parent,
+		Attributes: attrs,
+	}
+	if p.onSlot != nil {
+		p.onSlot(slot)
+	}
+
+	bid := p.relay.BestBid(slot.Number)
+	if bid == nil {
+		log.Info("No bid received, sealing the local payload", "slot", slot.Number)
+		return nil
+	}
+	data, err := checkPayload(bid.ExecutionPayload, slot)
+	if err != nil {
+		log.Warn("Invalid bid, sealing the local payload", "slot", slot.Number, "err", err)
+		return nil
+	}
+	return data
+}
+
+// checkPayload converts the payload of a bid and checks it is valid for the
+// slot.
+func checkPayload(payload *deneb.ExecutionPayload, slot *Slot) (*engine.ExecutableData, error) {
+	if common.Hash(payload.ParentHash) != slot.Parent {
+		return nil, fmt.Errorf("wrong parent %x", payload.ParentHash)
+	}
+	if payload.Timestamp != slot.Attributes.Timestamp {
+		return nil, fmt.Errorf("wrong timestamp %d", payload.Timestamp)
+	}
+	if common.Hash(payload.PrevRandao) != slot.Attributes.Random {
+		return nil, fmt.Errorf("wrong randomness %x", payload.PrevRandao)
+	}
+
+	data := &engine.ExecutableData{
+		ParentHash:    common.Hash(payload.ParentHash),
+		FeeRecipient:  common.Address(payload.FeeRecipient),
+		StateRoot:     common.Hash(payload.StateRoot),
+		ReceiptsRoot:  common.Hash(payload.ReceiptsRoot),
+		LogsBloom:     payload.LogsBloom[:],
+		Random:        common.Hash(payload.PrevRandao),
+		Number:        payload.BlockNumber,
+		GasLimit:      payload.GasLimit,
+		GasUsed:       payload.GasUsed,
+		Timestamp:     payload.Timestamp,
+		ExtraData:     payload.ExtraData,
+		BaseFeePerGas: payload.BaseFeePerGas.ToBig(),
+		BlockHash:     common.Hash(payload.BlockHash),
+		Transactions:  make([][]byte, len(payload.Transactions)),
+		Withdrawals:   make([]*types.Withdrawal, len(payload.Withdrawals)),
+		BlobGasUsed:   &payload.BlobGasUsed,
+		ExcessBlobGas: &payload.ExcessBlobGas,
+	}
+	var blobHashes []common.Hash
+	for i, enc := range payload.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		data.Transactions[i] = enc
+		blobHashes = append(blobHashes, tx.BlobHashes()...)
+	}
+	for i, wd := range payload.Withdrawals {
+		data.Withdrawals[i] = &types.Withdrawal{
+			Index:     uint64(wd.Index),
+			Validator: uint64(wd.ValidatorIndex),
+			Address:   common.Address(wd.Address),
+			Amount:    uint64(wd.Amount),
+		}
+	}
+	// the block hash commits to the beacon root, which is not part of the bid
+	if _, err := engine.ExecutableDataToBlock(*data, blobHashes, slot.Attributes.BeaconRoot); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
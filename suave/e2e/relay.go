@@ -0,0 +1,156 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	denebBuilder "github.com/attestantio/go-builder-client/api/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/suave/builder/api"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+)
+
+// SubmitBlockPath is the path of the block submission endpoint of the relay.
+const SubmitBlockPath = "/relay/v1/builder/blocks"
+
+var (
+	ErrIncompleteBid    = errors.New("bid without message or payload")
+	ErrPayloadMismatch  = errors.New("bid message does not match the payload")
+	ErrInvalidSignature = errors.New("invalid bid signature")
+)
+
+// builderDomain is the signing domain of the bids, the one used by the builder
+// sessions.
+var builderDomain = ssz.ComputeDomain(ssz.DomainTypeAppBuilder, phase0.Version{0x00, 0x00, 0x10, 0x20}, phase0.Root{})
+
+// Relay is an in-process stand-in for a relay. It accepts the blocks submitted
+// by the builders, verifies their signature and keeps the most valuable bid of
+// every slot.
+type Relay struct {
+	mu   sync.Mutex
+	bids map[uint64]*denebBuilder.SubmitBlockRequest
+}
+
+// NewRelay creates a relay without any bid.
+func NewRelay() *Relay {
+	return &Relay{
+		bids: make(map[uint64]*denebBuilder.SubmitBlockRequest),
+	}
+}
+
+// ServeHTTP serves the block submission endpoint.
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != SubmitBlockPath {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	var bid denebBuilder.SubmitBlockRequest
+	if err := json.NewDecoder(req.Body).Decode(&bid); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := r.SubmitBlock(&bid); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// SubmitBlock verifies the bid and keeps it if it is the most valuable one of
+// its slot so far.
+func (r *Relay) SubmitBlock(bid *denebBuilder.SubmitBlockRequest) error {
+	if bid.Message == nil || bid.ExecutionPayload == nil || bid.Message.Value == nil {
+		return ErrIncompleteBid
+	}
+	msg, payload := bid.Message, bid.ExecutionPayload
+	if msg.BlockHash != payload.BlockHash || msg.ParentHash != payload.ParentHash ||
+		msg.GasLimit != payload.GasLimit || msg.GasUsed != payload.GasUsed {
+		return ErrPayloadMismatch
+	}
+	ok, err := ssz.VerifySignature(msg, builderDomain, msg.BuilderPubkey[:], bid.Signature[:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if best, ok := r.bids[msg.Slot]; ok && best.Message.Value.Cmp(msg.Value) >= 0 {
+		return nil
+	}
+	r.bids[msg.Slot] = bid
+	return nil
+}
+
+// BestBid returns the most valuable bid received for the slot, if any.
+func (r *Relay) BestBid(slot uint64) *denebBuilder.SubmitBlockRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bids[slot]
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    code,
+		"message": err.Error(),
+	})
+}
+
+// SignBid signs the bid returned by a builder session with the secret key of
+// the builder, whose public key must have been given to the session. The
+// signing root is computed again since it is not sent over JSON-RPC.
+func SignBid(bid *api.SubmitBlockRequest, sk *bls.SecretKey) (*denebBuilder.SubmitBlockRequest, error) {
+	req := bid.SubmitBlockRequest
+	if req.Message == nil {
+		return nil, ErrIncompleteBid
+	}
+	sig, err := ssz.SignMessage(req.Message, builderDomain, sk)
+	if err != nil {
+		return nil, err
+	}
+	req.Signature = sig
+	return &req, nil
+}
+
+// SubmitBid sends the signed bid to the relay at the given URL.
+func SubmitBid(ctx context.Context, url string, bid *denebBuilder.SubmitBlockRequest) error {
+	data, err := json.Marshal(bid)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+SubmitBlockPath, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var body struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		return fmt.Errorf("relay rejected the bid (%d): %s", res.StatusCode, body.Message)
+	}
+	return nil
+}